@@ -0,0 +1,64 @@
+package orm
+
+import (
+	"strings"
+)
+
+// Aggregate functions
+const (
+	FuncSum   = "SUM"
+	FuncAvg   = "AVG"
+	FuncMin   = "MIN"
+	FuncMax   = "MAX"
+	FuncCount = "COUNT"
+)
+
+// Aggregation an aggregate function over a field
+type Aggregation struct {
+	Function string
+	Field    string // "*" is only allowed by COUNT
+	Alias    string // key in the result, default is field__function, e.g. amount__sum
+}
+
+func newAggregation(function string, field string) *Aggregation {
+	alias := field + "__" + strings.ToLower(function)
+	if field == "*" {
+		alias = strings.ToLower(function)
+	}
+	return &Aggregation{
+		Function: function,
+		Field:    field,
+		Alias:    alias,
+	}
+}
+
+// As rename the key in the result
+func (a *Aggregation) As(alias string) *Aggregation {
+	a.Alias = alias
+	return a
+}
+
+// Sum of the field
+func Sum(field string) *Aggregation {
+	return newAggregation(FuncSum, field)
+}
+
+// Avg average of the field
+func Avg(field string) *Aggregation {
+	return newAggregation(FuncAvg, field)
+}
+
+// Min minimum of the field
+func Min(field string) *Aggregation {
+	return newAggregation(FuncMin, field)
+}
+
+// Max maximum of the field
+func Max(field string) *Aggregation {
+	return newAggregation(FuncMax, field)
+}
+
+// Count count the non-null values of the field, or all rows with "*"
+func Count(field string) *Aggregation {
+	return newAggregation(FuncCount, field)
+}
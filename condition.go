@@ -0,0 +1,53 @@
+package orm
+
+// Condition is a node of the filter expression, it's either a *QueryParameter or a *Group
+type Condition interface {
+	condition()
+}
+
+// Connectors of Group
+const (
+	ConnectorAnd = "AND"
+	ConnectorOr  = "OR"
+)
+
+// Group combines conditions with AND or OR, the whole group can be negated
+type Group struct {
+	Connector  string
+	Negated    bool
+	Conditions []Condition
+}
+
+func (p *QueryParameter) condition() {}
+
+func (g *Group) condition() {}
+
+// And match when all conditions match
+func And(conditions ...Condition) Condition {
+	return &Group{
+		Connector:  ConnectorAnd,
+		Conditions: conditions,
+	}
+}
+
+// Or match when any condition matches, e.g.
+//
+//	table.Filter(
+//		orm.Or(orm.WithParameter("status", "a"), orm.WithParameter("status", "b")),
+//		orm.Not(orm.WithParameter("deleted", true)),
+//	)
+func Or(conditions ...Condition) Condition {
+	return &Group{
+		Connector:  ConnectorOr,
+		Conditions: conditions,
+	}
+}
+
+// Not match when the conditions don't match all together
+func Not(conditions ...Condition) Condition {
+	return &Group{
+		Connector:  ConnectorAnd,
+		Negated:    true,
+		Conditions: conditions,
+	}
+}
@@ -0,0 +1,55 @@
+package dialects
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zgljl2012/go-orm"
+)
+
+const (
+	// ErrPartialIndex the database doesn't support partial indexes
+	ErrPartialIndex = "partial index is not supported"
+)
+
+// quote wrap the identifier with q, q inside the identifier is doubled
+func quote(identifier string, q string) string {
+	return q + strings.Replace(identifier, q, q+q, -1) + q
+}
+
+// onConflict render the ON CONFLICT clause supported by SQLite and PostgreSQL
+func onConflict(d orm.Dialect, keys []string, updates []string) string {
+	target := ""
+	if len(keys) > 0 {
+		columns := []string{}
+		for _, key := range keys {
+			columns = append(columns, d.Quote(key))
+		}
+		target = "(" + strings.Join(columns, ",") + ") "
+	}
+	if len(updates) == 0 {
+		return "ON CONFLICT " + target + "DO NOTHING"
+	}
+	sets := []string{}
+	for _, column := range updates {
+		sets = append(sets, fmt.Sprintf("%s=excluded.%s", d.Quote(column), d.Quote(column)))
+	}
+	return "ON CONFLICT " + target + "DO UPDATE SET " + strings.Join(sets, ",")
+}
+
+// createIndex render the CREATE INDEX statement, the condition of partial index is appended as is
+func createIndex(d orm.Dialect, table string, index orm.Index) string {
+	columns := []string{}
+	for _, column := range index.Columns {
+		columns = append(columns, d.Quote(column))
+	}
+	sql := "CREATE "
+	if index.Unique {
+		sql += "UNIQUE "
+	}
+	sql += "INDEX " + d.Quote(index.Name) + " ON " + d.Quote(table) + " (" + strings.Join(columns, ",") + ")"
+	if index.Where != "" {
+		sql += " WHERE " + index.Where
+	}
+	return sql
+}
@@ -0,0 +1,119 @@
+package dialects_test
+
+import (
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/dialects"
+	"github.com/zgljl2012/go-orm/fields"
+)
+
+func TestDataType(t *testing.T) {
+	cases := []struct {
+		field    orm.Field
+		sqlite   string
+		postgres string
+	}{
+		{fields.NewIntField("id"), "INT", "INTEGER"},
+		{fields.NewFloatField("age"), "FLOAT", "REAL"},
+		{fields.NewCharField("name", fields.WithLength(20)), "CHAR(20)", "VARCHAR(20)"},
+		{fields.NewBoolField("active"), "BOOL", "BOOLEAN"},
+		{fields.NewDatetimeField("created_at"), "DATETIME", "TIMESTAMPTZ"},
+		{fields.NewUInt64Field("count"), "BIGINT", "BIGINT"},
+	}
+	sqlite, postgres := dialects.NewSQLite(), dialects.NewPostgres()
+	for _, c := range cases {
+		if tp := sqlite.DataType(c.field); tp != c.sqlite {
+			t.Errorf("sqlite type of %v should be %v, but got %v", c.field.Name(), c.sqlite, tp)
+		}
+		if tp := postgres.DataType(c.field); tp != c.postgres {
+			t.Errorf("postgres type of %v should be %v, but got %v", c.field.Name(), c.postgres, tp)
+		}
+	}
+}
+
+func TestPlaceholderAndQuote(t *testing.T) {
+	sqlite, postgres := dialects.NewSQLite(), dialects.NewPostgres()
+	if p := sqlite.Placeholder(3); p != "?" {
+		t.Errorf("expect ?, but got %v", p)
+	}
+	if p := postgres.Placeholder(3); p != "$3" {
+		t.Errorf("expect $3, but got %v", p)
+	}
+	if q := sqlite.Quote(`User`); q != `"User"` {
+		t.Errorf(`expect "User", but got %v`, q)
+	}
+	if q := postgres.Quote(`a"b`); q != `"a""b"` {
+		t.Errorf(`expect "a""b", but got %v`, q)
+	}
+}
+
+func TestLimitOffset(t *testing.T) {
+	cases := []struct {
+		limit    int
+		offset   int
+		sqlite   string
+		postgres string
+	}{
+		{0, 0, "", ""},
+		{5, 0, "LIMIT 5", "LIMIT 5"},
+		{0, 2, "LIMIT -1 OFFSET 2", "OFFSET 2"},
+		{5, 2, "LIMIT 5 OFFSET 2", "LIMIT 5 OFFSET 2"},
+	}
+	sqlite, postgres := dialects.NewSQLite(), dialects.NewPostgres()
+	for _, c := range cases {
+		if clause := sqlite.LimitOffset(c.limit, c.offset); clause != c.sqlite {
+			t.Errorf("expect %q, but got %q", c.sqlite, clause)
+		}
+		if clause := postgres.LimitOffset(c.limit, c.offset); clause != c.postgres {
+			t.Errorf("expect %q, but got %q", c.postgres, clause)
+		}
+	}
+}
+
+func TestSameType(t *testing.T) {
+	sqlite, postgres := dialects.NewSQLite(), dialects.NewPostgres()
+	cases := []struct {
+		columnType string
+		dataType   string
+		sqlite     bool
+		postgres   bool
+	}{
+		{columnType: "CHAR", dataType: "CHAR(20)", sqlite: true, postgres: false},
+		{columnType: "VARCHAR(20)", dataType: "VARCHAR(20)", sqlite: true, postgres: true},
+		{columnType: "INT", dataType: "INTEGER", sqlite: true, postgres: false},
+		{columnType: "INT", dataType: "CHAR(50)", sqlite: false, postgres: false},
+		{columnType: "BOOL", dataType: "DATETIME", sqlite: true, postgres: false},
+		{columnType: "FLOAT", dataType: "REAL", sqlite: true, postgres: false},
+	}
+	for _, c := range cases {
+		if got := sqlite.SameType(c.columnType, c.dataType); got != c.sqlite {
+			t.Errorf("sqlite: %v and %v expect %v, but got %v", c.columnType, c.dataType, c.sqlite, got)
+		}
+		if got := postgres.SameType(c.columnType, c.dataType); got != c.postgres {
+			t.Errorf("postgres: %v and %v expect %v, but got %v", c.columnType, c.dataType, c.postgres, got)
+		}
+	}
+}
+
+func TestOnConflict(t *testing.T) {
+	sqlite, mysql := dialects.NewSQLite(), dialects.NewMySQL()
+	cases := []struct {
+		keys    []string
+		updates []string
+		sqlite  string
+		mysql   string
+	}{
+		{[]string{"id"}, []string{"name"}, `ON CONFLICT ("id") DO UPDATE SET "name"=excluded."name"`, "ON DUPLICATE KEY UPDATE `name`=VALUES(`name`)"},
+		{[]string{"id"}, nil, `ON CONFLICT ("id") DO NOTHING`, "ON DUPLICATE KEY UPDATE `id`=`id`"},
+		{nil, nil, `ON CONFLICT DO NOTHING`, ""},
+	}
+	for _, c := range cases {
+		if clause := sqlite.OnConflict(c.keys, c.updates); clause != c.sqlite {
+			t.Errorf("expect %q, but got %q", c.sqlite, clause)
+		}
+		if clause := mysql.OnConflict(c.keys, c.updates); clause != c.mysql {
+			t.Errorf("expect %q, but got %q", c.mysql, clause)
+		}
+	}
+}
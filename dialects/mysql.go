@@ -0,0 +1,131 @@
+package dialects
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/fields"
+)
+
+type mysql struct{}
+
+// NewMySQL create the MySQL dialect
+func NewMySQL() orm.Dialect {
+	return &mysql{}
+}
+
+func (d *mysql) Name() string {
+	return "mysql"
+}
+
+func (d *mysql) Placeholder(n int) string {
+	return "?"
+}
+
+// Quote MySQL quotes identifiers with backticks
+func (d *mysql) Quote(identifier string) string {
+	return quote(identifier, "`")
+}
+
+func (d *mysql) DataType(field orm.Field) string {
+	switch field.Type() {
+	case fields.CHAR.String():
+		return fmt.Sprintf("CHAR(%d)", field.Length())
+	case fields.BOOL.String():
+		return "TINYINT(1)"
+	case fields.DATETIME.String():
+		return "DATETIME(6)"
+	case fields.UINT64.String():
+		return "BIGINT UNSIGNED"
+	}
+	return field.Type()
+}
+
+// LimitOffset MySQL needs a LIMIT before OFFSET, so use the max value of BIGINT UNSIGNED as no limit
+func (d *mysql) LimitOffset(limit, offset int) string {
+	var clauses []string
+	if limit > 0 {
+		clauses = append(clauses, fmt.Sprintf("LIMIT %d", limit))
+	} else if offset > 0 {
+		clauses = append(clauses, "LIMIT 18446744073709551615")
+	}
+	if offset > 0 {
+		clauses = append(clauses, fmt.Sprintf("OFFSET %d", offset))
+	}
+	return strings.Join(clauses, " ")
+}
+
+// OnConflict MySQL always checks all unique keys, so the keys are ignored
+func (d *mysql) OnConflict(keys []string, updates []string) string {
+	sets := []string{}
+	for _, column := range updates {
+		sets = append(sets, fmt.Sprintf("%s=VALUES(%s)", d.Quote(column), d.Quote(column)))
+	}
+	// nothing to update, assign the key to itself to keep the row,
+	// which can't be expressed without keys
+	if len(sets) == 0 {
+		if len(keys) == 0 {
+			return ""
+		}
+		sets = append(sets, fmt.Sprintf("%s=%s", d.Quote(keys[0]), d.Quote(keys[0])))
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ",")
+}
+
+func (d *mysql) MaxParameters() int {
+	return 65535
+}
+
+func (d *mysql) ColumnsQuery(table string) (string, []interface{}) {
+	return "SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE = 'YES' FROM information_schema.COLUMNS " +
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", []interface{}{table}
+}
+
+// displayWidth the display width of integers, e.g. INT(11), which is ignored by DataType
+var displayWidth = regexp.MustCompile(`^(INT|BIGINT)\(\d+\)`)
+
+func (d *mysql) NormalizeType(columnType string) string {
+	return displayWidth.ReplaceAllString(strings.ToUpper(columnType), "$1")
+}
+
+func (d *mysql) SameType(columnType, dataType string) bool {
+	return strings.EqualFold(columnType, dataType)
+}
+
+func (d *mysql) TableExistsQuery(table string) (string, []interface{}) {
+	return "SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?",
+		[]interface{}{table}
+}
+
+// Truncate TRUNCATE TABLE resets AUTO_INCREMENT too
+func (d *mysql) Truncate(table string) ([]string, string) {
+	return []string{"TRUNCATE TABLE " + d.Quote(table)}, ""
+}
+
+// SyncSequence AUTO_INCREMENT is moved by the inserted ids
+func (d *mysql) SyncSequence(table, column string) string {
+	return ""
+}
+
+func (d *mysql) IndexesQuery(table string) (string, []interface{}) {
+	return "SELECT DISTINCT INDEX_NAME FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?",
+		[]interface{}{table}
+}
+
+// CreateIndex MySQL doesn't support partial indexes
+func (d *mysql) CreateIndex(table string, index orm.Index) (string, error) {
+	if index.Where != "" {
+		return "", fmt.Errorf("%s: %s", ErrPartialIndex, index.Name)
+	}
+	return createIndex(d, table, index), nil
+}
+
+func (d *mysql) AutoIncrement(field orm.Field) (string, bool) {
+	return d.DataType(field) + " NOT NULL AUTO_INCREMENT", false
+}
+
+func (d *mysql) Returning(column string) string {
+	return ""
+}
@@ -0,0 +1,134 @@
+package dialects
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/fields"
+)
+
+type postgres struct{}
+
+// NewPostgres create the PostgreSQL dialect
+func NewPostgres() orm.Dialect {
+	return &postgres{}
+}
+
+func (d *postgres) Name() string {
+	return "postgres"
+}
+
+// Placeholder PostgreSQL uses numbered parameters: $1, $2...
+func (d *postgres) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (d *postgres) Quote(identifier string) string {
+	return quote(identifier, `"`)
+}
+
+func (d *postgres) DataType(field orm.Field) string {
+	switch field.Type() {
+	case fields.INT.String():
+		return "INTEGER"
+	case fields.FLOAT.String():
+		return "REAL"
+	case fields.CHAR.String():
+		return fmt.Sprintf("VARCHAR(%d)", field.Length())
+	case fields.BOOL.String():
+		return "BOOLEAN"
+	case fields.DATETIME.String():
+		return "TIMESTAMPTZ"
+	case fields.UINT64.String():
+		return "BIGINT"
+	}
+	return field.Type()
+}
+
+func (d *postgres) LimitOffset(limit, offset int) string {
+	var clauses []string
+	if limit > 0 {
+		clauses = append(clauses, fmt.Sprintf("LIMIT %d", limit))
+	}
+	if offset > 0 {
+		clauses = append(clauses, fmt.Sprintf("OFFSET %d", offset))
+	}
+	return strings.Join(clauses, " ")
+}
+
+func (d *postgres) OnConflict(keys []string, updates []string) string {
+	return onConflict(d, keys, updates)
+}
+
+func (d *postgres) MaxParameters() int {
+	return 65535
+}
+
+func (d *postgres) ColumnsQuery(table string) (string, []interface{}) {
+	return `SELECT a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull FROM pg_attribute a ` +
+			`WHERE a.attrelid = to_regclass($1) AND a.attnum > 0 AND NOT a.attisdropped ORDER BY a.attnum`,
+		[]interface{}{d.Quote(table)}
+}
+
+// postgresTypes map the types of format_type to the types of DataType
+var postgresTypes = map[string]string{
+	"integer":                  "INTEGER",
+	"real":                     "REAL",
+	"boolean":                  "BOOLEAN",
+	"timestamp with time zone": "TIMESTAMPTZ",
+	"bigint":                   "BIGINT",
+	"character varying":        "VARCHAR",
+	"character":                "CHAR",
+}
+
+func (d *postgres) NormalizeType(columnType string) string {
+	name, length := columnType, ""
+	if i := strings.Index(columnType, "("); i > 0 {
+		name, length = columnType[:i], columnType[i:]
+	}
+	if t, ok := postgresTypes[name]; ok {
+		return t + length
+	}
+	return strings.ToUpper(columnType)
+}
+
+func (d *postgres) SameType(columnType, dataType string) bool {
+	return strings.EqualFold(columnType, dataType)
+}
+
+func (d *postgres) TableExistsQuery(table string) (string, []interface{}) {
+	return `SELECT COUNT(to_regclass($1))`, []interface{}{d.Quote(table)}
+}
+
+func (d *postgres) Truncate(table string) ([]string, string) {
+	return []string{"TRUNCATE TABLE " + d.Quote(table) + " RESTART IDENTITY"}, ""
+}
+
+// SyncSequence the sequence of SERIAL isn't moved by the inserted ids, the next value is max+1,
+// which is 1 for empty tables
+func (d *postgres) SyncSequence(table, column string) string {
+	return "SELECT setval(pg_get_serial_sequence('" + strings.Replace(d.Quote(table), "'", "''", -1) + "', '" +
+		strings.Replace(column, "'", "''", -1) + "'), COALESCE(MAX(" + d.Quote(column) + "), 0) + 1, false) FROM " +
+		d.Quote(table)
+}
+
+func (d *postgres) IndexesQuery(table string) (string, []interface{}) {
+	return `SELECT indexname FROM pg_indexes WHERE schemaname = current_schema() AND tablename = $1`, []interface{}{table}
+}
+
+func (d *postgres) CreateIndex(table string, index orm.Index) (string, error) {
+	return createIndex(d, table, index), nil
+}
+
+func (d *postgres) AutoIncrement(field orm.Field) (string, bool) {
+	if field.Type() == fields.UINT64.String() {
+		return "BIGSERIAL NOT NULL", false
+	}
+	return "SERIAL NOT NULL", false
+}
+
+// Returning lib/pq doesn't support LastInsertId
+func (d *postgres) Returning(column string) string {
+	return "RETURNING " + d.Quote(column)
+}
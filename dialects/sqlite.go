@@ -0,0 +1,126 @@
+package dialects
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/fields"
+)
+
+type sqlite struct{}
+
+// NewSQLite create the SQLite dialect, it's the default dialect of tables
+func NewSQLite() orm.Dialect {
+	return &sqlite{}
+}
+
+func (d *sqlite) Name() string {
+	return "sqlite3"
+}
+
+func (d *sqlite) Placeholder(n int) string {
+	return "?"
+}
+
+func (d *sqlite) Quote(identifier string) string {
+	return quote(identifier, `"`)
+}
+
+func (d *sqlite) DataType(field orm.Field) string {
+	// only INTEGER PRIMARY KEY can be AUTOINCREMENT
+	if field.AutoIncrement() {
+		return "INTEGER"
+	}
+	if field.Type() == fields.CHAR.String() {
+		return fmt.Sprintf("CHAR(%d)", field.Length())
+	}
+	return field.Type()
+}
+
+// LimitOffset SQLite needs a LIMIT before OFFSET, -1 means no limit
+func (d *sqlite) LimitOffset(limit, offset int) string {
+	var clauses []string
+	if limit > 0 {
+		clauses = append(clauses, fmt.Sprintf("LIMIT %d", limit))
+	} else if offset > 0 {
+		clauses = append(clauses, "LIMIT -1")
+	}
+	if offset > 0 {
+		clauses = append(clauses, fmt.Sprintf("OFFSET %d", offset))
+	}
+	return strings.Join(clauses, " ")
+}
+
+func (d *sqlite) OnConflict(keys []string, updates []string) string {
+	return onConflict(d, keys, updates)
+}
+
+// MaxParameters SQLITE_MAX_VARIABLE_NUMBER defaults to 999 before 3.32.0
+func (d *sqlite) MaxParameters() int {
+	return 999
+}
+
+func (d *sqlite) ColumnsQuery(table string) (string, []interface{}) {
+	return `SELECT name, type, "notnull" = 0 FROM pragma_table_info(?) ORDER BY cid`, []interface{}{table}
+}
+
+func (d *sqlite) NormalizeType(columnType string) string {
+	return strings.ToUpper(columnType)
+}
+
+// SameType SQLite stores values by the affinity of column type, the length is ignored
+func (d *sqlite) SameType(columnType, dataType string) bool {
+	return affinity(columnType) == affinity(dataType)
+}
+
+// affinity return the type affinity of the declared column type, see https://www.sqlite.org/datatype3.html
+func affinity(columnType string) string {
+	t := strings.ToUpper(columnType)
+	switch {
+	case strings.Contains(t, "INT"):
+		return "INTEGER"
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return "TEXT"
+	case strings.Contains(t, "BLOB"), t == "":
+		return "BLOB"
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		return "REAL"
+	}
+	return "NUMERIC"
+}
+
+func (d *sqlite) TableExistsQuery(table string) (string, []interface{}) {
+	return `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, []interface{}{table}
+}
+
+// Truncate SQLite has no TRUNCATE, the sequences of AUTOINCREMENT are kept in sqlite_sequence,
+// which is created along with the first AUTOINCREMENT table
+func (d *sqlite) Truncate(table string) ([]string, string) {
+	return []string{
+		"DELETE FROM " + d.Quote(table),
+		"DELETE FROM " + d.Quote("sqlite_sequence") + " WHERE " + d.Quote("name") + " = '" + strings.Replace(table, "'", "''", -1) + "'",
+	}, "sqlite_sequence"
+}
+
+// SyncSequence sqlite_sequence is updated by the inserted ids
+func (d *sqlite) SyncSequence(table, column string) string {
+	return ""
+}
+
+func (d *sqlite) IndexesQuery(table string) (string, []interface{}) {
+	return `SELECT name FROM pragma_index_list(?)`, []interface{}{table}
+}
+
+func (d *sqlite) CreateIndex(table string, index orm.Index) (string, error) {
+	return createIndex(d, table, index), nil
+}
+
+// AutoIncrement SQLite declares AUTOINCREMENT along with the primary key, so the table can't have other primary keys
+func (d *sqlite) AutoIncrement(field orm.Field) (string, bool) {
+	return d.DataType(field) + " NOT NULL PRIMARY KEY AUTOINCREMENT", true
+}
+
+func (d *sqlite) Returning(column string) string {
+	return ""
+}
@@ -0,0 +1,14 @@
+package orm
+
+import (
+	"errors"
+)
+
+var (
+	// ErrNotFound no row matches
+	ErrNotFound = errors.New("orm: no rows found")
+	// ErrMultipleRows more than one row matches when exactly one is expected
+	ErrMultipleRows = errors.New("orm: multiple rows found")
+	// ErrStaleObject the row has been updated by others since it was read, it should be read again before retrying
+	ErrStaleObject = errors.New("orm: stale object")
+)
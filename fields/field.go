@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/zgljl2012/go-orm"
 	"github.com/zgljl2012/slog"
@@ -61,6 +62,140 @@ func parseFieldOptions(field reflect.StructField) ([]FieldOption, error) {
 				}
 			},
 		},
+		{
+			tag:   "autoIncrement",
+			_type: reflect.Bool,
+			validators: []valueValidator{
+				boolValidator,
+			},
+			fun: func(value string) FieldOption {
+				return WithAutoIncrement(value == "true")
+			},
+		},
+		{
+			tag:   "autoCreateTime",
+			_type: reflect.Bool,
+			validators: []valueValidator{
+				boolValidator,
+			},
+			fun: func(value string) FieldOption {
+				return WithAutoCreateTime(value == "true")
+			},
+		},
+		{
+			tag:   "autoUpdateTime",
+			_type: reflect.Bool,
+			validators: []valueValidator{
+				boolValidator,
+			},
+			fun: func(value string) FieldOption {
+				return WithAutoUpdateTime(value == "true")
+			},
+		},
+		{
+			tag:   "softDelete",
+			_type: reflect.Bool,
+			validators: []valueValidator{
+				boolValidator,
+			},
+			fun: func(value string) FieldOption {
+				return WithSoftDelete(value == "true")
+			},
+		},
+		{
+			tag:   "version",
+			_type: reflect.Bool,
+			validators: []valueValidator{
+				boolValidator,
+			},
+			fun: func(value string) FieldOption {
+				return WithVersion(value == "true")
+			},
+		},
+		{
+			tag:   "default",
+			_type: reflect.String,
+			validators: []valueValidator{
+				func(value string) error {
+					_, err := parseDefault(field, value)
+					return err
+				},
+			},
+			fun: func(value string) FieldOption {
+				v, _ := parseDefault(field, value)
+				return WithDefault(v)
+			},
+		},
+		{
+			tag:   "unique",
+			_type: reflect.Bool,
+			validators: []valueValidator{
+				boolValidator,
+			},
+			fun: func(value string) FieldOption {
+				return WithUnique(value == "true")
+			},
+		},
+		{
+			tag:   "index",
+			_type: reflect.String,
+			validators: []valueValidator{
+				func(value string) error {
+					_, err := parseIndexTag(value)
+					return err
+				},
+			},
+			fun: func(value string) FieldOption {
+				indexes, _ := parseIndexTag(value)
+				return func(options *FieldOptions) {
+					options.Indexes = append(options.Indexes, indexes...)
+				}
+			},
+		},
+		{
+			tag:   "fk",
+			_type: reflect.String,
+			validators: []valueValidator{
+				func(value string) error {
+					if i := strings.LastIndex(value, "."); i <= 0 || i == len(value)-1 {
+						return fmt.Errorf(`parse fk tag error, it should be "Table.column": "%s"`, value)
+					}
+					return nil
+				},
+			},
+			fun: func(value string) FieldOption {
+				i := strings.LastIndex(value, ".")
+				return func(options *FieldOptions) {
+					if options.ForeignKey == nil {
+						options.ForeignKey = &orm.ForeignKey{}
+					}
+					options.ForeignKey.Table = value[:i]
+					options.ForeignKey.Column = value[i+1:]
+				}
+			},
+		},
+		{
+			// onDelete works with fk tag
+			tag:   "onDelete",
+			_type: reflect.String,
+			validators: []valueValidator{
+				func(value string) error {
+					switch strings.ToUpper(value) {
+					case "CASCADE", "SET NULL", "SET DEFAULT", "RESTRICT", "NO ACTION":
+						return nil
+					}
+					return fmt.Errorf(`can't support "%v" for onDelete tag`, value)
+				},
+			},
+			fun: func(value string) FieldOption {
+				return func(options *FieldOptions) {
+					if options.ForeignKey == nil {
+						options.ForeignKey = &orm.ForeignKey{}
+					}
+					options.ForeignKey.OnDelete = strings.ToUpper(value)
+				}
+			},
+		},
 		{
 			tag:   "null",
 			_type: reflect.Bool,
@@ -108,6 +243,72 @@ func parseFieldOptions(field reflect.StructField) ([]FieldOption, error) {
 	return options, nil
 }
 
+// parseDefault convert the default tag to the type of field,
+// the default of datetime field can only be CURRENT_TIMESTAMP, which is applied by the database
+func parseDefault(field reflect.StructField, value string) (interface{}, error) {
+	var (
+		v   interface{}
+		err error
+	)
+	switch field.Type.Kind() {
+	case reflect.Int:
+		v, err = strconv.Atoi(value)
+	case reflect.Uint64:
+		v, err = strconv.ParseUint(value, 10, 64)
+	case reflect.Float32:
+		var f float64
+		f, err = strconv.ParseFloat(value, 32)
+		v = float32(f)
+	case reflect.Bool:
+		v, err = strconv.ParseBool(value)
+	case reflect.String:
+		v = value
+	default:
+		if field.Type.String() == "time.Time" && strings.ToUpper(value) == "CURRENT_TIMESTAMP" {
+			return orm.Expr("CURRENT_TIMESTAMP"), nil
+		}
+		err = fmt.Errorf("unsupported")
+	}
+	if err != nil {
+		return nil, fmt.Errorf(`parse default tag error, field: "%s", default: "%s", err: "%s"`, field.Name, value, err)
+	}
+	return v, nil
+}
+
+// parseIndexTag parse the index tag, indexes are separated by semicolon,
+// every index is its name followed by options separated by comma, e.g.
+// `index:"idx_name"`, `index:"idx_a;idx_b,unique"`, `index:"idx_name,unique,where:deleted_at IS NULL"`.
+// The where option must be the last one, since the condition may contain commas.
+func parseIndexTag(value string) ([]orm.Index, error) {
+	indexes := []orm.Index{}
+	for _, item := range strings.Split(value, ";") {
+		index := orm.Index{}
+		rest := strings.TrimSpace(item)
+		if i := strings.Index(rest, "where:"); i >= 0 {
+			index.Where = strings.TrimSpace(rest[i+len("where:"):])
+			rest = strings.TrimRight(strings.TrimSpace(rest[:i]), ",")
+			if index.Where == "" {
+				return nil, fmt.Errorf(`parse index tag error, the condition is empty: "%s"`, value)
+			}
+		}
+		for i, option := range strings.Split(rest, ",") {
+			option = strings.TrimSpace(option)
+			if i == 0 {
+				index.Name = option
+			} else if option == "unique" {
+				index.Unique = true
+			} else {
+				return nil, fmt.Errorf(`parse index tag error, unknown option "%s": "%s"`, option, value)
+			}
+		}
+		if index.Name == "" {
+			return nil, fmt.Errorf(`parse index tag error, the name is empty: "%s"`, value)
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
 // ParseStructWithTagsToFields parse the struct's fields with tags to orm.field
 func ParseStructWithTagsToFields(instance interface{}) ([]orm.Field, error) {
 	// TODO: 校验 name 命名的合法性；校验 name 是否重复
@@ -128,6 +329,24 @@ func ParseStructWithTagsToFields(instance interface{}) ([]orm.Field, error) {
 			if err != nil {
 				return nil, err
 			}
+			if field.Tag.Get("autoIncrement") == "true" {
+				if field.Tag.Get("primaryKey") != "true" || (kind != reflect.Int && kind != reflect.Uint64) {
+					return nil, fmt.Errorf(`autoIncrement field "%s" should be an integer primary key`, field.Name)
+				}
+			}
+			if field.Tag.Get("version") == "true" && kind != reflect.Int && kind != reflect.Uint64 {
+				return nil, fmt.Errorf(`version field "%s" should be an integer`, field.Name)
+			}
+			for _, tag := range []string{"autoCreateTime", "autoUpdateTime", "softDelete"} {
+				if field.Tag.Get(tag) == "true" && field.Type.String() != "time.Time" {
+					return nil, fmt.Errorf(`%s field "%s" should be time.Time`, tag, field.Name)
+				}
+			}
+			if _, ok := field.Tag.Lookup("fk"); !ok {
+				if _, ok := field.Tag.Lookup("onDelete"); ok {
+					return nil, fmt.Errorf(`onDelete tag of field "%s" needs the fk tag`, field.Name)
+				}
+			}
 			var f orm.Field
 			if kind == reflect.Int {
 				f = newFiled(field.Name, name, INT, options...)
@@ -187,19 +406,17 @@ func NewCharField(name string, opts ...FieldOption) orm.Field {
 
 // Type return type
 func (f *myField) Type() string {
-	var t string
+	return f._type.String()
+}
 
-	if f._type == CHAR {
-		t = f._type.String() + fmt.Sprintf("(%d)", f.options.Length)
-	} else {
-		t = f._type.String()
-	}
-	if !f.options.Null || f.PrimaryKey() {
-		t += " NOT NULL"
-	} else if !f.PrimaryKey() {
-		t += " NULL"
-	}
-	return t
+// Length return the length of char field
+func (f *myField) Length() int {
+	return f.options.Length
+}
+
+// Null primary key can't be null
+func (f *myField) Null() bool {
+	return f.options.Null && !f.PrimaryKey()
 }
 
 func (f *myField) Name() string {
@@ -213,3 +430,43 @@ func (f *myField) ID() string {
 func (f *myField) PrimaryKey() bool {
 	return f.options.PrimaryKey
 }
+
+func (f *myField) Default() interface{} {
+	return f.options.Default
+}
+
+func (f *myField) DefaultFunc() func() interface{} {
+	return f.options.DefaultFunc
+}
+
+func (f *myField) AutoIncrement() bool {
+	return f.options.AutoIncrement
+}
+
+func (f *myField) AutoCreateTime() bool {
+	return f.options.AutoCreateTime
+}
+
+func (f *myField) AutoUpdateTime() bool {
+	return f.options.AutoUpdateTime
+}
+
+func (f *myField) SoftDelete() bool {
+	return f.options.SoftDelete
+}
+
+func (f *myField) Version() bool {
+	return f.options.Version
+}
+
+func (f *myField) Unique() bool {
+	return f.options.Unique
+}
+
+func (f *myField) Indexes() []orm.Index {
+	return f.options.Indexes
+}
+
+func (f *myField) ForeignKey() *orm.ForeignKey {
+	return f.options.ForeignKey
+}
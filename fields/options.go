@@ -1,12 +1,30 @@
 package fields
 
+import "github.com/zgljl2012/go-orm"
+
 // Function Options Pattern
 
 // FieldOptions options of field
 type FieldOptions struct {
-	PrimaryKey bool
+	PrimaryKey    bool
+	AutoIncrement bool
+	// AutoCreateTime stamp the time field on adding
+	AutoCreateTime bool
+	// AutoUpdateTime stamp the time field on adding and updating
+	AutoUpdateTime bool
+	// SoftDelete the time field marks the row deleted
+	SoftDelete bool
+	// Version the integer field is used for optimistic locking
+	Version    bool
 	Length     int
 	Null       bool
+	Unique     bool
+	Indexes    []orm.Index
+	ForeignKey *orm.ForeignKey
+	// Default the default value of column
+	Default interface{}
+	// DefaultFunc compute the value in Go when it's zero on adding
+	DefaultFunc func() interface{}
 }
 
 var defaultOptions = FieldOptions{
@@ -25,6 +43,41 @@ func WithPrimaryKey(set bool) FieldOption {
 	}
 }
 
+// WithAutoIncrement set the primary key be generated by the database
+func WithAutoIncrement(set bool) FieldOption {
+	return func(options *FieldOptions) {
+		options.AutoIncrement = set
+	}
+}
+
+// WithAutoCreateTime set the time field be stamped with the current time when it's zero on adding
+func WithAutoCreateTime(set bool) FieldOption {
+	return func(options *FieldOptions) {
+		options.AutoCreateTime = set
+	}
+}
+
+// WithAutoUpdateTime set the time field be stamped with the current time on adding and updating
+func WithAutoUpdateTime(set bool) FieldOption {
+	return func(options *FieldOptions) {
+		options.AutoUpdateTime = set
+	}
+}
+
+// WithSoftDelete set the time field marks the row deleted, Delete sets it instead of deleting the row
+func WithSoftDelete(set bool) FieldOption {
+	return func(options *FieldOptions) {
+		options.SoftDelete = set
+	}
+}
+
+// WithVersion set the integer field be the version of row, Update checks and increases it
+func WithVersion(set bool) FieldOption {
+	return func(options *FieldOptions) {
+		options.Version = set
+	}
+}
+
 // WithLength set the length
 func WithLength(length int) FieldOption {
 	return func(options *FieldOptions) {
@@ -38,3 +91,41 @@ func WithNull(null bool) FieldOption {
 		options.Null = null
 	}
 }
+
+// WithUnique set the field be unique
+func WithUnique(set bool) FieldOption {
+	return func(options *FieldOptions) {
+		options.Unique = set
+	}
+}
+
+// WithIndex add the field into index, fields with the same index name make a composite index,
+// the columns of index are filled by the table
+func WithIndex(index orm.Index) FieldOption {
+	return func(options *FieldOptions) {
+		options.Indexes = append(options.Indexes, index)
+	}
+}
+
+// WithForeignKey set the column referenced by the field
+func WithForeignKey(fk orm.ForeignKey) FieldOption {
+	return func(options *FieldOptions) {
+		options.ForeignKey = &fk
+	}
+}
+
+// WithDefault set the default value of column, it's applied by the database since the column is omitted
+// from INSERT if the field is zero, so the zero value can't be added. Use orm.Expr for SQL expressions,
+// e.g. orm.Expr("CURRENT_TIMESTAMP").
+func WithDefault(value interface{}) FieldOption {
+	return func(options *FieldOptions) {
+		options.Default = value
+	}
+}
+
+// WithDefaultFunc set the function which computes the value when the field is zero on adding, e.g. time.Now
+func WithDefaultFunc(fn func() interface{}) FieldOption {
+	return func(options *FieldOptions) {
+		options.DefaultFunc = fn
+	}
+}
@@ -0,0 +1,9 @@
+package orm
+
+// Index an index of table, fields which have indexes with the same name make a composite index
+type Index struct {
+	Name    string
+	Columns []string // columns in order of fields, filled by the table
+	Unique  bool
+	Where   string // the condition of a partial index
+}
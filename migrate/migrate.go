@@ -0,0 +1,302 @@
+// Package migrate runs versioned migrations in order and records them in a history table.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/tables"
+	log "github.com/zgljl2012/slog"
+)
+
+const (
+	// ErrDuplicateVersion the version has been registered
+	ErrDuplicateVersion = "migration version has been registered"
+	// ErrEmptyVersion the version of migration is empty
+	ErrEmptyVersion = "migration version is empty"
+	// ErrIrreversible the migration doesn't have a down function
+	ErrIrreversible = "migration is irreversible"
+	// ErrUnknownVersion the applied version is not registered
+	ErrUnknownVersion = "migration version is not registered"
+	// ErrInvalidFileName the name of migration file is not <version>_<name>.(up|down).sql
+	ErrInvalidFileName = "invalid migration file name"
+)
+
+// Func a migration step, it runs inside the transaction of tx
+type Func func(tx orm.Session) error
+
+// Migration a versioned migration
+type Migration struct {
+	Version string
+	Name    string
+	Up      Func
+	Down    Func
+}
+
+// Status the state of a migration
+type Status struct {
+	Version   string
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// schemaMigration a row of history table
+type schemaMigration struct {
+	Version   string    `name:"version" length:"255" primaryKey:"true"`
+	Name      string    `name:"name" length:"255"`
+	AppliedAt time.Time `name:"applied_at"`
+}
+
+// Migrator apply and revert migrations
+type Migrator struct {
+	ctx        context.Context
+	db         *sql.DB
+	history    orm.Table
+	migrations map[string]*Migration
+}
+
+// New create a migrator
+func New(db *sql.DB, opts ...Option) (*Migrator, error) {
+	options := defaultOptions()
+	for _, o := range opts {
+		o(&options)
+	}
+	history, err := tables.NewStructTagsTable(db, &schemaMigration{},
+		tables.WithDialect(options.Dialect), tables.WithName(options.Table))
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		ctx:        context.Background(),
+		db:         db,
+		history:    history,
+		migrations: map[string]*Migration{},
+	}, nil
+}
+
+// WithContext return a copy of migrator which runs with ctx
+func (m *Migrator) WithContext(ctx context.Context) *Migrator {
+	c := *m
+	c.ctx = ctx
+	c.history = m.history.WithContext(ctx)
+	return &c
+}
+
+// Register register a migration, down can be nil if the migration is irreversible.
+// Migrations are applied in the order of their versions, which are compared as strings,
+// so use fixed-width versions, e.g. 0001 or 20200405082946.
+func (m *Migrator) Register(version, name string, up, down Func) error {
+	if version == "" {
+		return fmt.Errorf(ErrEmptyVersion)
+	}
+	if _, ok := m.migrations[version]; ok {
+		return fmt.Errorf("%s: %s", ErrDuplicateVersion, version)
+	}
+	m.migrations[version] = &Migration{Version: version, Name: name, Up: up, Down: down}
+	return nil
+}
+
+// RegisterSQL register a migration with SQL scripts, down can be empty if the migration is irreversible
+func (m *Migrator) RegisterSQL(version, name, up, down string) error {
+	var downFunc Func
+	if down != "" {
+		downFunc = execSQL(down)
+	}
+	return m.Register(version, name, execSQL(up), downFunc)
+}
+
+// execSQL return the migration step which executes sql with the context of migrator
+func execSQL(sql string) Func {
+	return func(tx orm.Session) error {
+		log.Debug(sql)
+		_, err := tx.Tx().ExecContext(tx.Context(), sql)
+		return err
+	}
+}
+
+// LoadDir register the SQL migrations in dir,
+// the files are named <version>_<name>.up.sql and <version>_<name>.down.sql
+func (m *Migrator) LoadDir(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	type scripts struct {
+		name, up, down string
+	}
+	found := map[string]*scripts{}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".sql") {
+			continue
+		}
+		base := strings.TrimSuffix(file.Name(), ".sql")
+		direction := filepath.Ext(base)
+		if direction != ".up" && direction != ".down" {
+			return fmt.Errorf("%s: %s", ErrInvalidFileName, file.Name())
+		}
+		base = strings.TrimSuffix(base, direction)
+		version, name := base, ""
+		if i := strings.Index(base, "_"); i >= 0 {
+			version, name = base[:i], base[i+1:]
+		}
+		if version == "" {
+			return fmt.Errorf("%s: %s", ErrInvalidFileName, file.Name())
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return err
+		}
+		s, ok := found[version]
+		if !ok {
+			s = &scripts{name: name}
+			found[version] = s
+		}
+		if direction == ".up" {
+			s.up = string(content)
+		} else {
+			s.down = string(content)
+		}
+	}
+	for version, s := range found {
+		if s.up == "" {
+			return fmt.Errorf("%s: the up script of %s is not found", ErrInvalidFileName, version)
+		}
+		if err := m.RegisterSQL(version, s.name, s.up, s.down); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// versions return the registered versions in order
+func (m *Migrator) versions() []string {
+	versions := make([]string, 0, len(m.migrations))
+	for version := range m.migrations {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// applied return the applied migrations in order of versions
+func (m *Migrator) applied() ([]schemaMigration, error) {
+	if err := m.history.Create(true); err != nil {
+		return nil, err
+	}
+	rows, err := m.history.Filter().OrderBy("version").All()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]schemaMigration, len(rows))
+	for i, row := range rows {
+		result[i] = row.(schemaMigration)
+	}
+	return result, nil
+}
+
+// Up apply all pending migrations in order, each one in its own transaction,
+// return the versions which are applied
+func (m *Migrator) Up() ([]string, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	done := map[string]bool{}
+	for _, row := range applied {
+		done[row.Version] = true
+	}
+	versions := []string{}
+	for _, version := range m.versions() {
+		if done[version] {
+			continue
+		}
+		migration := m.migrations[version]
+		err := orm.TransactionContext(m.ctx, m.db, func(tx orm.Session) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Table(m.history).Add(&schemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now().UTC(),
+			})
+		})
+		if err != nil {
+			log.Error("apply migration error", "version", version, "err", err)
+			return versions, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// Down revert the latest applied migration, return its version,
+// the version is empty if there are no applied migrations
+func (m *Migrator) Down() (string, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return "", err
+	}
+	if len(applied) == 0 {
+		return "", nil
+	}
+	latest := applied[len(applied)-1]
+	migration, ok := m.migrations[latest.Version]
+	if !ok {
+		return "", fmt.Errorf("%s: %s", ErrUnknownVersion, latest.Version)
+	}
+	if migration.Down == nil {
+		return "", fmt.Errorf("%s: %s", ErrIrreversible, latest.Version)
+	}
+	err = orm.TransactionContext(m.ctx, m.db, func(tx orm.Session) error {
+		if err := migration.Down(tx); err != nil {
+			return err
+		}
+		return tx.Table(m.history).Delete(&latest)
+	})
+	if err != nil {
+		log.Error("revert migration error", "version", latest.Version, "err", err)
+		return "", err
+	}
+	return latest.Version, nil
+}
+
+// Status list the applied and pending migrations in order of versions,
+// applied migrations which are not registered are listed too
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	statuses := map[string]*Status{}
+	for _, version := range m.versions() {
+		statuses[version] = &Status{Version: version, Name: m.migrations[version].Name}
+	}
+	for _, row := range applied {
+		status, ok := statuses[row.Version]
+		if !ok {
+			status = &Status{Version: row.Version, Name: row.Name}
+			statuses[row.Version] = status
+		}
+		status.Applied = true
+		status.AppliedAt = row.AppliedAt
+	}
+	versions := make([]string, 0, len(statuses))
+	for version := range statuses {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	result := make([]Status, len(versions))
+	for i, version := range versions {
+		result[i] = *statuses[version]
+	}
+	return result, nil
+}
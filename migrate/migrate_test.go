@@ -0,0 +1,219 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/migrate"
+	log "github.com/zgljl2012/slog"
+)
+
+var (
+	testDB = "./test.db"
+)
+
+func createTestDatabase() *sql.DB {
+	db, err := sql.Open("sqlite3", testDB)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return db
+}
+
+func deleteTestDatabase() {
+	if _, err := os.Stat(testDB); err == nil {
+		if err := os.Remove(testDB); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
+
+func newMigrator(t *testing.T, db *sql.DB) *migrate.Migrator {
+	m, err := migrate.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Register("0001", "add_users", func(tx orm.Session) error {
+		_, err := tx.Tx().Exec(`CREATE TABLE "user"("id" INT NOT NULL, PRIMARY KEY("id"))`)
+		return err
+	}, func(tx orm.Session) error {
+		_, err := tx.Tx().Exec(`DROP TABLE "user"`)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.LoadDir("testdata"); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestMigrate(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	m := newMigrator(t, db)
+
+	// duplicate version
+	if err := m.RegisterSQL("0001", "again", "SELECT 1", ""); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+
+	// pending
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("expect 3 migrations, but got %d", len(statuses))
+	}
+	for i, name := range []string{"add_users", "add_posts", "seed_posts"} {
+		if statuses[i].Name != name || statuses[i].Applied {
+			t.Errorf("status %d is wrong: %+v", i, statuses[i])
+		}
+	}
+
+	// up
+	versions, err := m.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 || versions[0] != "0001" || versions[2] != "0003" {
+		t.Errorf("applied versions are wrong: %v", versions)
+	}
+	if !tableExists(t, db, "user") || !tableExists(t, db, "post") {
+		t.Error("tables should be created")
+	}
+	statuses, err = m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if !status.Applied || status.AppliedAt.IsZero() {
+			t.Errorf("migration should be applied: %+v", status)
+		}
+	}
+
+	// nothing to apply
+	if versions, err := newMigrator(t, db).Up(); err != nil || len(versions) != 0 {
+		t.Errorf("nothing should be applied: %v, %v", versions, err)
+	}
+
+	// 0003 is irreversible
+	if _, err := m.Down(); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if _, err := db.Exec(`DELETE FROM "schema_migrations" WHERE "version" = '0003'`); err != nil {
+		t.Fatal(err)
+	}
+
+	// down
+	version, err := m.Down()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "0002" || tableExists(t, db, "post") {
+		t.Errorf("0002 should be reverted, but got %s", version)
+	}
+	if version, err = m.Down(); err != nil || version != "0001" {
+		t.Errorf("0001 should be reverted: %s, %v", version, err)
+	}
+	if version, err = m.Down(); err != nil || version != "" {
+		t.Errorf("nothing should be reverted: %s, %v", version, err)
+	}
+}
+
+func TestMigrateFailure(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	m, err := migrate.New(db, migrate.WithTable("history"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RegisterSQL("1", "ok", `CREATE TABLE "a"("id" INT)`, `DROP TABLE "a"`); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RegisterSQL("2", "broken", `CREATE TABLE "b"("id" INT)`, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Register("3", "failed", func(tx orm.Session) error {
+		if _, err := tx.Tx().Exec(`CREATE TABLE "c"("id" INT)`); err != nil {
+			return err
+		}
+		_, err := tx.Tx().Exec(`INSERT INTO "missing" VALUES (1)`)
+		return err
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := m.Up()
+	if err == nil {
+		t.Fatal("should got an error, but is normal")
+	}
+	if len(versions) != 2 {
+		t.Errorf("expect 2 applied versions, but got %v", versions)
+	}
+	// the failed migration is rolled back
+	if tableExists(t, db, "c") {
+		t.Error("table c should be rolled back")
+	}
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !statuses[0].Applied || !statuses[1].Applied || statuses[2].Applied {
+		t.Errorf("statuses are wrong: %+v", statuses)
+	}
+}
+
+// contextKey is the key of value in the context of migrator
+type contextKey struct{}
+
+func TestMigrateContext(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	m, err := migrate.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey{}, "value"))
+	m = m.WithContext(ctx)
+	if err := m.Register("1", "context", func(tx orm.Session) error {
+		if tx.Context().Value(contextKey{}) != "value" {
+			t.Error("migration should run with the context of migrator")
+		}
+		return nil
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	// SQL migrations are canceled along with the context
+	cancel()
+	if err := m.RegisterSQL("2", "canceled", `CREATE TABLE "a"("id" INT)`, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if tableExists(t, db, "a") {
+		t.Error("table a should not be created")
+	}
+}
@@ -0,0 +1,37 @@
+package migrate
+
+import (
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/dialects"
+)
+
+// Options options of migrator
+type Options struct {
+	Dialect orm.Dialect
+	// Table the name of history table
+	Table string
+}
+
+func defaultOptions() Options {
+	return Options{
+		Dialect: dialects.NewSQLite(),
+		Table:   "schema_migrations",
+	}
+}
+
+// Option option setter
+type Option func(options *Options)
+
+// WithDialect set the dialect of database, default is SQLite
+func WithDialect(dialect orm.Dialect) Option {
+	return func(options *Options) {
+		options.Dialect = dialect
+	}
+}
+
+// WithTable set the name of history table, default is schema_migrations
+func WithTable(table string) Option {
+	return func(options *Options) {
+		options.Table = table
+	}
+}
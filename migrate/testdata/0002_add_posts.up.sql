@@ -0,0 +1 @@
+CREATE TABLE "post"("id" INT NOT NULL, "title" CHAR(100) NULL, PRIMARY KEY("id"));
@@ -0,0 +1,2 @@
+INSERT INTO "post" VALUES (1, 'hello');
+INSERT INTO "post" VALUES (2, 'world');
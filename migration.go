@@ -0,0 +1,21 @@
+package orm
+
+// MigrationReport result of Table.Migrate
+type MigrationReport struct {
+	Created bool          // the table didn't exist and has been created
+	Added   []string      // columns added
+	Drifts  []ColumnDrift // columns whose type or nullability differ from the fields
+	Unknown []string      // columns in the table but not in the fields, they are left untouched
+	Pending []string      // NOT NULL columns without default, they can only be added by rebuilding
+	Rebuilt bool          // the table has been rebuilt to fix drifts or add pending columns
+	Indexes []string      // indexes created
+}
+
+// ColumnDrift the difference between a column and its field
+type ColumnDrift struct {
+	Column       string
+	Type         string // type in the database
+	ExpectedType string // type of the field
+	Null         bool   // nullable in the database
+	ExpectedNull bool   // nullable of the field
+}
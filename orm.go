@@ -1,11 +1,86 @@
 package orm
 
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
 // Field field interface
 type Field interface {
 	ID() string       // name in struct
 	Name() string     // name
-	Type() string     // the type of this field, e.g. int, float
+	Type() string     // the type of this field, e.g. INT, FLOAT, CHAR
+	Length() int      // length of char field
+	Null() bool       // nullable
 	PrimaryKey() bool // primary key
+	// AutoIncrement the value is generated by the database when it's zero, only for integer primary keys
+	AutoIncrement() bool
+	Unique() bool     // unique, it's created as an unique index
+	Indexes() []Index // indexes which contain this field
+	// AutoCreateTime the time field is stamped with the current time when it's zero on adding
+	AutoCreateTime() bool
+	// AutoUpdateTime the time field is stamped with the current time on adding and updating
+	AutoUpdateTime() bool
+	// SoftDelete the time field marks the row deleted, the row is deleted by setting it rather than DELETE
+	SoftDelete() bool
+	// Version the integer field is checked and increased by updating, for optimistic locking
+	Version() bool
+	// ForeignKey the referenced column, nil if the field doesn't reference another table
+	ForeignKey() *ForeignKey
+	// Default the default value of column, nil if there isn't
+	Default() interface{}
+	// DefaultFunc compute the value when it's zero on adding, nil if there isn't
+	DefaultFunc() func() interface{}
+}
+
+// Expr is a SQL expression which is rendered as is, e.g. the default value CURRENT_TIMESTAMP
+type Expr string
+
+// Dialect hides the SQL differences between databases
+type Dialect interface {
+	// Name of the database, e.g. sqlite3, postgres
+	Name() string
+	// Placeholder return the bind parameter of the n-th value, n starts from 1
+	Placeholder(n int) string
+	// Quote quote an identifier, e.g. table name, column name
+	Quote(identifier string) string
+	// DataType return the column type of the field in this database
+	DataType(field Field) string
+	// AutoIncrement return the column definition after the name of the auto increment field,
+	// primaryKey reports whether the definition declares the primary key
+	AutoIncrement(field Field) (definition string, primaryKey bool)
+	// Returning return the clause appended to INSERT which returns the generated column,
+	// empty if the database reports it by LastInsertId
+	Returning(column string) string
+	// LimitOffset return the LIMIT/OFFSET clause, empty if both of them are zero
+	LimitOffset(limit, offset int) string
+	// MaxParameters the max number of parameters in one statement
+	MaxParameters() int
+	// ColumnsQuery return the query which lists the columns of table, every row is (name, type, nullable)
+	ColumnsQuery(table string) (string, []interface{})
+	// NormalizeType convert the column type listed by ColumnsQuery to the form of DataType
+	NormalizeType(columnType string) string
+	// SameType report whether the column type normalized by NormalizeType stores the same values as the
+	// type returned by DataType, e.g. SQLite compares the type affinities, so CHAR is the same as CHAR(20)
+	SameType(columnType, dataType string) bool
+	// TableExistsQuery return the query which counts the tables named table
+	TableExistsQuery(table string) (string, []interface{})
+	// IndexesQuery return the query which lists the names of the indexes of table
+	IndexesQuery(table string) (string, []interface{})
+	// CreateIndex return the statement which creates the index on table
+	CreateIndex(table string, index Index) (string, error)
+	// Truncate return the statements which delete all rows of table and reset its auto increment sequence.
+	// If sequences is not empty, the statements after the first one reset the sequence and they are
+	// run only when the table named sequences exists
+	Truncate(table string) (statements []string, sequences string)
+	// SyncSequence return the statement which moves the auto increment sequence of column past the max value,
+	// it's run after the rows are copied with their ids. Return empty if the database does it by itself
+	SyncSequence(table, column string) string
+	// OnConflict return the clause appended to INSERT which updates the columns when keys conflict,
+	// or does nothing if updates is empty. Return empty if the database can't upsert natively,
+	// or can't do nothing without keys
+	OnConflict(keys []string, updates []string) string
 }
 
 // Table table
@@ -16,17 +91,55 @@ type Table interface {
 	Name() string
 	// Add
 	Add(instance interface{}) error
-	// Upsert add or update
-	Upsert(instance interface{}) error
-	// Delete operate will delete via primary keys
+	// AddMany insert a slice of instances in one transaction, batchSize limits the rows of each INSERT statement
+	AddMany(instances interface{}, batchSize int) error
+	// Upsert add or update in one statement, by default all fields except primary keys are updated when
+	// primary keys conflict, you can specify the conflict target and the fields to update with options.
+	// It's rejected if the table has a version field.
+	Upsert(instance interface{}, opts ...UpsertOption) error
+	// AddOrIgnore add the instance, or do nothing if it conflicts with an existing row
+	AddOrIgnore(instance interface{}, opts ...UpsertOption) error
+	// Delete operate will delete via primary keys, the row is only marked deleted if the table has a soft delete field
 	Delete(instance interface{}) error
+	// HardDelete delete the row via primary keys even if the table has a soft delete field
+	HardDelete(instance interface{}) error
+	// Restore clear the soft delete field of the row
+	Restore(instance interface{}) error
 	// Update operate will select those row via primary keys, then update other fields.
 	// So your should be sure of your primary keys won't be updated.
+	// If the table has a version field, the row is updated only if the version is unchanged,
+	// the version is increased, otherwise ErrStaleObject is returned.
 	Update(instance interface{}) error
 	// Filter rows
-	Filter(...*QueryParameter) FilterSet
+	Filter(...Condition) FilterSet
 	// Count get the counts
 	Count(instance interface{}) (int, error)
+	// Migrate compare the fields with the columns of the existing table, add missing columns and report drifts.
+	// If rebuild is true, the table will be rebuilt for the drifts which can't be altered, existing data is copied.
+	// The table will be created if it doesn't exist.
+	Migrate(rebuild bool) (*MigrationReport, error)
+	// Drop the table, you can pass a parameter to skip dropping if the table doesn't exist
+	Drop(ifExists bool) error
+	// Truncate delete all rows and reset the auto increment sequence
+	Truncate() error
+	// Rename the table, return a copy of the table which operates on newName, the receiver is left unchanged
+	Rename(newName string) (Table, error)
+	// TableExists check whether the table exists in the database
+	TableExists() (bool, error)
+	// Associate add the related instances to the many-to-many relation of owner, relation is the name of
+	// the field with m2m tag. Existing associations are ignored.
+	Associate(owner interface{}, relation string, related ...interface{}) error
+	// Dissociate remove the related instances from the many-to-many relation of owner,
+	// all associations of owner are removed if related is empty
+	Dissociate(owner interface{}, relation string, related ...interface{}) error
+	// Related return the rows related to owner by the many-to-many relation
+	Related(owner interface{}, relation string) FilterSet
+	// WithContext return a copy of the table whose operations run with ctx,
+	// so that they can be canceled or given a deadline
+	WithContext(ctx context.Context) Table
+	// WithTx return a copy of the table whose operations run inside tx,
+	// the caller is responsible for committing or rolling back tx
+	WithTx(tx *sql.Tx) Table
 }
 
 // QueryParameter for filter
@@ -36,26 +149,146 @@ type QueryParameter struct {
 	Operator string // 操作符
 }
 
-// WithParameter create paramter pair
+// Operators of QueryParameter
+const (
+	OpEqual          = "="
+	OpNotEqual       = "<>"
+	OpGreaterThan    = ">"
+	OpGreaterOrEqual = ">="
+	OpLessThan       = "<"
+	OpLessOrEqual    = "<="
+	OpContains       = "contains"   // value is a substring
+	OpIContains      = "icontains"  // case-insensitive contains
+	OpStartsWith     = "startswith" // value is a prefix
+	OpEndsWith       = "endswith"   // value is a suffix
+	OpIn             = "in"         // value is a slice
+	OpRange          = "range"      // value is a slice of two bounds, both inclusive
+	OpIsNull         = "isnull"     // value is a bool
+)
+
+// lookups map the suffix of name to operator
+var lookups = map[string]string{
+	"exact":      OpEqual,
+	"ne":         OpNotEqual,
+	"gt":         OpGreaterThan,
+	"gte":        OpGreaterOrEqual,
+	"lt":         OpLessThan,
+	"lte":        OpLessOrEqual,
+	"contains":   OpContains,
+	"icontains":  OpIContains,
+	"startswith": OpStartsWith,
+	"endswith":   OpEndsWith,
+	"in":         OpIn,
+	"range":      OpRange,
+	"isnull":     OpIsNull,
+}
+
+// WithParameter create paramter pair, the operator can be specified by a lookup suffix of name, e.g.
+//
+//	WithParameter("age__gte", 18)
+//	WithParameter("name__contains", "bob")
+//	WithParameter("id__in", []int{1, 2, 3})
+//	WithParameter("created_at__range", []time.Time{start, end})
+//	WithParameter("deleted_at__isnull", true)
+//
+// supported lookups: exact, ne, gt, gte, lt, lte, contains, icontains, startswith, endswith, in, range, isnull
 func WithParameter(name string, value interface{}) *QueryParameter {
+	operator := OpEqual
+	if i := strings.LastIndex(name, "__"); i > 0 {
+		if op, ok := lookups[name[i+2:]]; ok {
+			name, operator = name[:i], op
+		}
+	}
 	return &QueryParameter{
 		Name:     name,
 		Value:    value,
-		Operator: "=",
+		Operator: operator,
 	}
 }
 
 // FilterSet for select
 // you can iterate FilterSet via range
 type FilterSet interface {
-	// Filter with paramters
-	Filter(parameters ...*QueryParameter) FilterSet
+	// Filter with conditions, multiple conditions are joined with AND
+	Filter(conditions ...Condition) FilterSet
+	// Exclude rows which match all the conditions
+	Exclude(conditions ...Condition) FilterSet
 	// OrderBy specify ordering fields, plus means ASC, minus(-) means DESC
 	OrderBy(...string) FilterSet
 	// Limit rows
 	Limit(int) FilterSet
 	// Offset set offset
 	Offset(int) FilterSet
+	// WithContext return a copy of the filter set which runs the query with ctx
+	WithContext(ctx context.Context) FilterSet
+	// WithDeleted include the soft deleted rows, which are excluded by default
+	WithDeleted() FilterSet
+	// OnlyDeleted return only the soft deleted rows
+	OnlyDeleted() FilterSet
+	// Preload fill the relation fields of the rows returned by All, First, Last and Get,
+	// relations are loaded with one IN query per relation
+	Preload(relations ...string) FilterSet
 	// All return all rows, returned data just an array of objects, not pointer.
-	All() []interface{}
+	All() ([]interface{}, error)
+	// Count return the number of filtered rows
+	Count() (int, error)
+	// Exists return true if any row matches
+	Exists() (bool, error)
+	// First scan the first row into dst, dst should be a pointer of the table struct,
+	// rows are ordered by primary keys if no ordering is specified. Return ErrNotFound if no row matches.
+	First(dst interface{}) error
+	// Last is the same as First, but in reversed order
+	Last(dst interface{}) error
+	// Get scan the only matched row into dst, return ErrNotFound if no row matches,
+	// or ErrMultipleRows if more than one row matches
+	Get(dst interface{}) error
+	// Update update all filtered rows in one statement, keys of values are field names.
+	// Return the number of affected rows.
+	Update(values map[string]interface{}) (int64, error)
+	// Delete delete all filtered rows in one statement, return the number of affected rows.
+	// The rows are only marked deleted if the table has a soft delete field
+	Delete() (int64, error)
+	// Iter return a cursor which scans rows one by one, so that big tables can be processed in constant memory
+	Iter() (Cursor, error)
+	// Aggregate compute the aggregations over all filtered rows, the result is keyed by the alias of aggregations
+	Aggregate(aggregations ...*Aggregation) (map[string]interface{}, error)
+	// GroupBy group the filtered rows by fields
+	GroupBy(fields ...string) GroupSet
+}
+
+// GroupSet rows grouped by fields
+//
+//	rows, err := table.Filter().GroupBy("customer").
+//		Having(orm.WithParameter("amount__sum__gt", 100)).
+//		OrderBy("-amount__sum").
+//		Aggregate(orm.Sum("amount"))
+type GroupSet interface {
+	// Having filter groups, names can be the alias of aggregations or the grouping fields
+	Having(conditions ...Condition) GroupSet
+	// OrderBy specify ordering, names can be the alias of aggregations or the grouping fields
+	OrderBy(...string) GroupSet
+	// Aggregate compute the aggregations of each group, every row contains the grouping fields keyed by column name
+	Aggregate(aggregations ...*Aggregation) ([]map[string]interface{}, error)
+}
+
+// Cursor iterate rows of a FilterSet, you should close it after iterating
+//
+//	cursor, err := table.Filter().Iter()
+//	defer cursor.Close()
+//	user := User{}
+//	for cursor.Next() {
+//		if err := cursor.Scan(&user); err != nil {
+//			...
+//		}
+//	}
+//	err = cursor.Err()
+type Cursor interface {
+	// Next prepare the next row, return false if there are no more rows or an error occurs
+	Next() bool
+	// Scan copy the current row into dst, dst should be a pointer of the table struct
+	Scan(dst interface{}) error
+	// Err return the error occurred during iteration
+	Err() error
+	// Close close the cursor
+	Close() error
 }
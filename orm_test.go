@@ -1,6 +1,7 @@
 package orm_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
@@ -305,7 +306,10 @@ func TestFilterSet(t *testing.T) {
 
 	// Filter
 	filter := table.Filter()
-	rows := filter.All()
+	rows, err := filter.All()
+	if err != nil {
+		t.Fatal(err)
+	}
 	id := 1
 	for _, row := range rows {
 		user := row.(User)
@@ -326,7 +330,10 @@ func TestFilterSet(t *testing.T) {
 
 	// validate
 	filter = table.Filter()
-	rows = filter.All()
+	rows, err = filter.All()
+	if err != nil {
+		t.Fatal(err)
+	}
 	id = 1
 	for _, row := range rows {
 		user := row.(User)
@@ -341,7 +348,10 @@ func TestFilterSet(t *testing.T) {
 
 	// filter with id=1
 	filter = table.Filter(orm.WithParameter("ID", 1))
-	rows = filter.All()
+	rows, err = filter.All()
+	if err != nil {
+		t.Fatal(err)
+	}
 	if len(rows) != 1 {
 		t.Error("You should only filter one row")
 	}
@@ -351,19 +361,29 @@ func TestFilterSet(t *testing.T) {
 	}
 
 	// orderby
-	user1 = table.Filter().OrderBy("-ID").All()[0].(User)
+	rows, err = table.Filter().OrderBy("-ID").All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user1 = rows[0].(User)
 	if user1.ID != 10 {
 		t.Errorf("ID of this user should be 10, but got %v", user1.ID)
 	}
 
 	// limit
-	rows = table.Filter().Limit(5).All()
+	rows, err = table.Filter().Limit(5).All()
+	if err != nil {
+		t.Fatal(err)
+	}
 	if len(rows) != 5 {
 		t.Errorf("rows'cnt should be 5, but got %v", len(rows))
 	}
 
 	// offset
-	rows = table.Filter().Offset(2).All()
+	rows, err = table.Filter().Offset(2).All()
+	if err != nil {
+		t.Fatal(err)
+	}
 	if rows[0].(User).ID != 3 {
 		t.Errorf("expected 3, but got %v", rows[0].(User).ID)
 	}
@@ -388,3 +408,119 @@ func TestStructTags(t *testing.T) {
 	}
 
 }
+
+func TestTransaction(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Create(true); err != nil {
+		t.Fatal(err)
+	}
+
+	// commit
+	if err := orm.Transaction(db, func(tx orm.Session) error {
+		users := tx.Table(table)
+		if err := users.Add(&User{ID: 1, Username: "username1"}); err != nil {
+			return err
+		}
+		return users.Update(&User{ID: 1, Username: "username2"})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if rows, err := table.Filter().All(); err != nil {
+		t.Error(err)
+	} else if len(rows) != 1 || rows[0].(User).Username != "username2" {
+		t.Errorf("transaction should be committed, but got %v", rows)
+	}
+
+	// rollback when got an error
+	if err := orm.Transaction(db, func(tx orm.Session) error {
+		if err := tx.Table(table).Add(&User{ID: 2}); err != nil {
+			return err
+		}
+		// duplicated
+		return tx.Table(table).Add(&User{ID: 1})
+	}); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if cnt, err := table.Count(&User{ID: 2}); err != nil || cnt != 0 {
+		t.Errorf("transaction should be rolled back, count: %v, err: %v", cnt, err)
+	}
+
+	// rollback when panic
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("the panic should be re-raised")
+			}
+		}()
+		_ = orm.Transaction(db, func(tx orm.Session) error {
+			if err := tx.Table(table).Add(&User{ID: 3}); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	}()
+	if cnt, err := table.Count(&User{ID: 3}); err != nil || cnt != 0 {
+		t.Errorf("transaction should be rolled back, count: %v, err: %v", cnt, err)
+	}
+
+	// statements are canceled along with the context of transaction
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := orm.TransactionContext(ctx, db, func(tx orm.Session) error {
+		cancel()
+		return tx.Table(table).Add(&User{ID: 5})
+	}); err != context.Canceled {
+		t.Errorf("expect context.Canceled, but got %v", err)
+	}
+
+	// bind a table to an existing transaction
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.WithTx(tx).Add(&User{ID: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if rows, err := table.WithTx(tx).Filter().All(); err != nil {
+		t.Error(err)
+	} else if len(rows) != 2 {
+		t.Errorf("rows'cnt should be 2 inside the transaction, but got %v", len(rows))
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if rows, err := table.Filter().All(); err != nil {
+		t.Error(err)
+	} else if len(rows) != 1 {
+		t.Errorf("rows'cnt should be 1 after rollback, but got %v", len(rows))
+	}
+}
+
+func TestWithParameter(t *testing.T) {
+	cases := []struct {
+		name     string
+		expect   string
+		operator string
+	}{
+		{"ID", "ID", orm.OpEqual},
+		{"age__gte", "age", orm.OpGreaterOrEqual},
+		{"name__contains", "name", orm.OpContains},
+		{"id__in", "id", orm.OpIn},
+		{"created_at__range", "created_at", orm.OpRange},
+		{"deleted_at__isnull", "deleted_at", orm.OpIsNull},
+		// not a lookup
+		{"author__name", "author__name", orm.OpEqual},
+	}
+	for _, c := range cases {
+		parameter := orm.WithParameter(c.name, 1)
+		if parameter.Name != c.expect || parameter.Operator != c.operator {
+			t.Errorf("%v: expect %v %v, but got %v %v", c.name, c.expect, c.operator, parameter.Name, parameter.Operator)
+		}
+	}
+}
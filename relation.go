@@ -0,0 +1,8 @@
+package orm
+
+// ForeignKey the column of another table referenced by a field
+type ForeignKey struct {
+	Table    string
+	Column   string
+	OnDelete string // action when the referenced row is deleted, e.g. CASCADE, SET NULL, empty means the default
+}
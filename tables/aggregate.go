@@ -0,0 +1,203 @@
+package tables
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zgljl2012/go-orm"
+	log "github.com/zgljl2012/slog"
+)
+
+type groupSet struct {
+	filter *filterSet
+	fields []string
+	having []orm.Condition
+	order  []string
+}
+
+// GroupBy group the filtered rows by fields
+func (f *filterSet) GroupBy(fields ...string) orm.GroupSet {
+	return &groupSet{
+		filter: f,
+		fields: fields,
+		having: []orm.Condition{},
+	}
+}
+
+// Having filter groups
+func (g *groupSet) Having(conditions ...orm.Condition) orm.GroupSet {
+	g.having = append(g.having, conditions...)
+	return g
+}
+
+// OrderBy specify ordering, plus means ASC, minus(-) means DESC
+func (g *groupSet) OrderBy(orders ...string) orm.GroupSet {
+	g.order = append(g.order, orders...)
+	return g
+}
+
+// Aggregate compute the aggregations of each group
+func (g *groupSet) Aggregate(aggregations ...*orm.Aggregation) ([]map[string]interface{}, error) {
+	return g.filter.aggregate(g.fields, g.having, g.order, aggregations)
+}
+
+// Aggregate compute the aggregations over all filtered rows
+func (f *filterSet) Aggregate(aggregations ...*orm.Aggregation) (map[string]interface{}, error) {
+	if len(aggregations) == 0 {
+		return nil, fmt.Errorf(ErrNoAggregations)
+	}
+	rows, err := f.aggregate(nil, nil, nil, aggregations)
+	if err != nil {
+		return nil, err
+	}
+	// aggregate without grouping always returns one row
+	return rows[0], nil
+}
+
+// aggregation render the aggregation to SQL
+func (t *simpleTable) aggregation(aggregation *orm.Aggregation, resolve resolver) (string, error) {
+	switch aggregation.Function {
+	case orm.FuncSum, orm.FuncAvg, orm.FuncMin, orm.FuncMax, orm.FuncCount:
+	default:
+		return "", fmt.Errorf(`%s: "%s"`, ErrUnsupportedFunction, aggregation.Function)
+	}
+	if aggregation.Field == "*" && aggregation.Function == orm.FuncCount {
+		return "COUNT(*)", nil
+	}
+	column, err := resolve(aggregation.Field)
+	if err != nil {
+		return "", err
+	}
+	return aggregation.Function + "(" + column + ")", nil
+}
+
+// aggregate run the aggregations grouped by groups, groups can be empty
+func (f *filterSet) aggregate(groups []string, having []orm.Condition, order []string, aggregations []*orm.Aggregation) ([]map[string]interface{}, error) {
+	var (
+		t       = f.table
+		p       = newParams(t.dialect)
+		keys    []string
+		columns []string
+		grouped []string
+		// aliases of aggregations to their expressions
+		expressions = map[string]string{}
+	)
+	// fields across relations are joined
+	names := append([]string{}, groups...)
+	for _, aggregation := range aggregations {
+		if aggregation.Field != "*" {
+			names = append(names, aggregation.Field)
+		}
+	}
+	j, err := f.joins(names)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		column, err := j.column(group)
+		if err != nil {
+			return nil, err
+		}
+		// the key of a field of this table is its column name
+		if field, err := t.field(group); err == nil {
+			group = field.Name()
+		}
+		keys = append(keys, group)
+		columns = append(columns, column)
+		grouped = append(grouped, column)
+	}
+	for _, aggregation := range aggregations {
+		expression, err := t.aggregation(aggregation, j.column)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, aggregation.Alias)
+		columns = append(columns, expression+" AS "+t.quote(aggregation.Alias))
+		expressions[aggregation.Alias] = expression
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf(ErrNoAggregations)
+	}
+
+	sql := "SELECT " + strings.Join(columns, ",") + " FROM " + j.from()
+	where, err := f.where(p, j.column)
+	if err != nil {
+		return nil, err
+	}
+	if where != "" {
+		sql += " WHERE " + where
+	}
+	if len(grouped) > 0 {
+		sql += " GROUP BY " + strings.Join(grouped, ",")
+	}
+
+	// having, names are aliases of aggregations or grouping fields
+	resolve := func(name string) (string, error) {
+		if expression, ok := expressions[name]; ok {
+			return expression, nil
+		}
+		return j.column(name)
+	}
+	conditions := []string{}
+	for _, condition := range having {
+		rendered, err := t.where(condition, resolve, p)
+		if err != nil {
+			return nil, err
+		}
+		if rendered != "" {
+			conditions = append(conditions, rendered)
+		}
+	}
+	if len(conditions) > 0 {
+		sql += " HAVING " + strings.Join(conditions, " AND ")
+	}
+
+	// order
+	var orders []string
+	for _, o := range order {
+		o = strings.Trim(o, " ")
+		name := strings.TrimPrefix(o, "-")
+		column, err := resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := expressions[name]; ok {
+			column = t.quote(name)
+		}
+		if strings.HasPrefix(o, "-") {
+			column += " DESC"
+		}
+		orders = append(orders, column)
+	}
+	if len(orders) > 0 {
+		sql += " ORDER BY " + strings.Join(orders, ",")
+	}
+
+	log.Debug(sql)
+	result := []map[string]interface{}{}
+	err = t.query(f.ctx, sql, p.values, func(row scanner) error {
+		values := make([]interface{}, len(keys))
+		dest := make([]interface{}, len(keys))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := row.Scan(dest...); err != nil {
+			return err
+		}
+		item := map[string]interface{}{}
+		for i, key := range keys {
+			// some drivers return numbers as text
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			item[key] = values[i]
+		}
+		result = append(result, item)
+		return nil
+	})
+	if err != nil {
+		log.Error("aggregate error", "err", err)
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package tables_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+func TestAggregate(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Create(true); err != nil {
+		t.Fatal(err)
+	}
+
+	// ages: 10, 20, ..., 100, the even ones are active
+	for i := 1; i <= 10; i++ {
+		user := User{ID: i, Username: fmt.Sprintf("user%d", i), Age: float32(10 * i), Active: i%2 == 0}
+		if err := table.Add(&user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := table.Filter(orm.WithParameter("ID__lte", 4)).Aggregate(
+		orm.Sum("age"), orm.Avg("age"), orm.Min("age"), orm.Max("Age").As("oldest"), orm.Count("*"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]string{
+		"age__sum": "100",
+		"age__avg": "25",
+		"age__min": "10",
+		"oldest":   "40",
+		"count":    "4",
+	}
+	for key, value := range expect {
+		if fmt.Sprint(result[key]) != value {
+			t.Errorf("%v should be %v, but got %v", key, value, result[key])
+		}
+	}
+
+	// group by
+	rows, err := table.Filter().GroupBy("active").OrderBy("-age__sum").Aggregate(orm.Sum("age"), orm.Count("ID"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("should be 2 groups, but got %v", len(rows))
+	}
+	if fmt.Sprint(rows[0]["age__sum"], rows[0]["ID__count"]) != "300 5" ||
+		fmt.Sprint(rows[1]["age__sum"], rows[1]["ID__count"]) != "250 5" {
+		t.Errorf("groups are wrong: %v", rows)
+	}
+	if _, ok := rows[0]["active"]; !ok {
+		t.Errorf("grouping field should be in the result: %v", rows[0])
+	}
+
+	// having
+	rows, err = table.Filter().GroupBy("active").
+		Having(orm.WithParameter("age__sum__gt", 260)).
+		Aggregate(orm.Sum("age"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || fmt.Sprint(rows[0]["age__sum"]) != "300" {
+		t.Errorf("having is wrong: %v", rows)
+	}
+
+	// errors
+	if _, err := table.Filter().Aggregate(); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if _, err := table.Filter().Aggregate(orm.Sum("unknown")); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if _, err := table.Filter().Aggregate(orm.Sum("*")); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if _, err := table.Filter().GroupBy("active").Having(orm.WithParameter("unknown__gt", 1)).Aggregate(orm.Sum("age")); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+}
@@ -0,0 +1,187 @@
+package tables_test
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/dialects"
+	"github.com/zgljl2012/go-orm/fields"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+// Item is a test table with auto increment primary key
+type Item struct {
+	ID   int    `name:"id" primaryKey:"true" autoIncrement:"true"`
+	Name string `name:"name" length:"20"`
+}
+
+func TestAutoIncrement(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &Item{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Create(false); err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := Item{Name: "first"}, Item{Name: "second"}
+	if err := table.Add(&first); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Add(&second); err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("generated ids are wrong: %d, %d", first.ID, second.ID)
+	}
+
+	// specified id
+	if err := table.Add(&Item{ID: 10, Name: "ten"}); err != nil {
+		t.Fatal(err)
+	}
+	next := Item{Name: "next"}
+	if err := table.Add(&next); err != nil {
+		t.Fatal(err)
+	}
+	if next.ID != 11 {
+		t.Errorf("id should be 11, but got %d", next.ID)
+	}
+
+	// batch
+	if err := table.AddMany([]Item{{Name: "a"}, {Name: "b"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := table.Filter().Count(); err != nil || count != 6 {
+		t.Errorf("expect 6 rows, but got %d: %v", count, err)
+	}
+
+	// mixed batch, the rows without ids are inserted by another statement
+	if err := table.AddMany([]Item{{ID: 20, Name: "c"}, {Name: "d"}, {Name: "e"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := table.Filter(orm.WithParameter("Name__in", []string{"c", "d", "e"})).OrderBy("ID").All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(rows) != "[{20 c} {21 d} {22 e}]" {
+		t.Errorf("rows of mixed batch are wrong: %v", rows)
+	}
+
+	// the sequence is reset
+	if err := table.Truncate(); err != nil {
+		t.Fatal(err)
+	}
+	item := Item{Name: "again"}
+	if err := table.Add(&item); err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != 1 {
+		t.Errorf("id should be 1 after truncating, but got %d", item.ID)
+	}
+
+	// the table is up to date
+	report, err := table.Migrate(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Drifts) != 0 {
+		t.Errorf("there should be no drifts: %+v", report.Drifts)
+	}
+
+	// autoIncrement needs an integer primary key
+	if _, err := tables.NewStructTagsTable(db, &struct {
+		ID   int    `name:"id" primaryKey:"true"`
+		Name string `name:"name" length:"20" autoIncrement:"true"`
+	}{}); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+}
+
+// Counter is a test table with unsigned auto increment primary key
+type Counter struct {
+	ID   uint32
+	Name string
+}
+
+func TestAutoIncrementUnsigned(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewTable(db, &fieldsTable{fields: []orm.Field{
+		fields.NewIntField("ID", fields.WithPrimaryKey(true), fields.WithAutoIncrement(true)),
+		fields.NewCharField("Name", fields.WithLength(20)),
+	}}, tables.WithName("Counter"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	counter := Counter{Name: "first"}
+	if err := table.Add(&counter); err != nil {
+		t.Fatal(err)
+	}
+	if counter.ID != 1 {
+		t.Errorf("generated id is wrong: %d", counter.ID)
+	}
+
+	// the struct field of an auto increment field should be an integer, fieldsTable.fields is a slice
+	if _, err := tables.NewTable(db, &fieldsTable{fields: []orm.Field{
+		fields.NewIntField("fields", fields.WithPrimaryKey(true), fields.WithAutoIncrement(true)),
+	}}); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+}
+
+func TestAutoIncrementSQL(t *testing.T) {
+	db := createRecordingDatabase()
+	postgres, err := tables.NewStructTagsTable(db, &Item{}, tables.WithDialect(dialects.NewPostgres()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := postgres.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	expect := `CREATE TABLE "Item"("id" SERIAL NOT NULL,"name" VARCHAR(20) NULL, PRIMARY KEY("id"))`
+	if sql, _ := rec.last(); sql != expect {
+		t.Errorf("expect %v\nbut got %v", expect, sql)
+	}
+	if err := postgres.Add(&Item{Name: "name"}); err != nil {
+		t.Fatal(err)
+	}
+	expect = `INSERT INTO "Item" ("name") VALUES ($1) RETURNING "id"`
+	if sql, _ := rec.last(); sql != expect {
+		t.Errorf("expect %v\nbut got %v", expect, sql)
+	}
+
+	// the sequence continues after the copied ids when the table is rebuilt
+	rec.returns([]driver.Value{"id", "integer", false}, []driver.Value{"name", "integer", true})
+	if report, err := postgres.Migrate(true); err != nil || !report.Rebuilt {
+		t.Fatalf("table should be rebuilt: %+v, %v", report, err)
+	}
+	expect = `SELECT setval(pg_get_serial_sequence('"Item"', 'id'), COALESCE(MAX("id"), 0) + 1, false) FROM "Item"`
+	found := false
+	for _, sql := range rec.statements {
+		found = found || sql == expect
+	}
+	if !found {
+		t.Errorf("expect %v\nbut got %v", expect, rec.statements)
+	}
+
+	mysql, err := tables.NewStructTagsTable(db, &Item{}, tables.WithDialect(dialects.NewMySQL()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mysql.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	expect = "CREATE TABLE `Item`(`id` INT NOT NULL AUTO_INCREMENT,`name` CHAR(20) NULL, PRIMARY KEY(`id`))"
+	if sql, _ := rec.last(); sql != expect {
+		t.Errorf("expect %v\nbut got %v", expect, sql)
+	}
+}
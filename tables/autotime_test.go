@@ -0,0 +1,117 @@
+package tables_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/fields"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+// Note is a test table with auto time fields
+type Note struct {
+	ID        int       `name:"id" primaryKey:"true"`
+	Text      string    `name:"text" length:"20"`
+	CreatedAt time.Time `name:"created_at" autoCreateTime:"true"`
+	UpdatedAt time.Time `name:"updated_at" autoUpdateTime:"true"`
+}
+
+func TestAutoTime(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	table, err := tables.NewStructTagsTable(db, &Note{}, tables.WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Create(false); err != nil {
+		t.Fatal(err)
+	}
+
+	created := now
+	note := Note{ID: 1, Text: "a"}
+	if err := table.Add(&note); err != nil {
+		t.Fatal(err)
+	}
+	if !note.CreatedAt.Equal(created) || !note.UpdatedAt.Equal(created) {
+		t.Errorf("times are not stamped on adding: %+v", note)
+	}
+
+	now = now.Add(time.Hour)
+	note.Text = "b"
+	if err := table.Update(&note); err != nil {
+		t.Fatal(err)
+	}
+	got := Note{}
+	if err := table.Filter(orm.WithParameter("ID", 1)).Get(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.CreatedAt.Equal(created) || !got.UpdatedAt.Equal(now) {
+		t.Errorf("times are wrong after updating: %+v", got)
+	}
+
+	// the creation time of the existing row is kept on upserting
+	now = now.Add(time.Hour)
+	if err := table.Upsert(&Note{ID: 1, Text: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Filter(orm.WithParameter("ID", 1)).Get(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Text != "c" || !got.CreatedAt.Equal(created) || !got.UpdatedAt.Equal(now) {
+		t.Errorf("times are wrong after upserting: %+v", got)
+	}
+
+	// specified creation time is kept
+	specified := Note{ID: 2, CreatedAt: created}
+	if err := table.Add(&specified); err != nil {
+		t.Fatal(err)
+	}
+	if !specified.CreatedAt.Equal(created) || !specified.UpdatedAt.Equal(now) {
+		t.Errorf("times are wrong: %+v", specified)
+	}
+
+	// bulk update stamps the update time unless it's given
+	now = now.Add(time.Hour)
+	if _, err := table.Filter(orm.WithParameter("ID", 1)).Update(map[string]interface{}{"Text": "d"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Filter(orm.WithParameter("ID", 1)).Get(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Text != "d" || !got.CreatedAt.Equal(created) || !got.UpdatedAt.Equal(now) {
+		t.Errorf("times are wrong after bulk updating: %+v", got)
+	}
+	if _, err := table.Filter(orm.WithParameter("ID", 1)).Update(map[string]interface{}{"UpdatedAt": created}); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Filter(orm.WithParameter("ID", 1)).Get(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.UpdatedAt.Equal(created) {
+		t.Errorf("given update time should be kept: %+v", got)
+	}
+}
+
+func TestAutoTimeTagError(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	_, err := tables.NewStructTagsTable(db, &struct {
+		ID int `name:"id" primaryKey:"true" autoCreateTime:"true"`
+	}{})
+	if err == nil {
+		t.Error("autoCreateTime of int field should be rejected")
+	}
+
+	// fieldsTable.fields is a slice
+	_, err = tables.NewTable(db, &fieldsTable{fields: []orm.Field{
+		fields.NewIntField("ID", fields.WithPrimaryKey(true)),
+		fields.NewDatetimeField("fields", fields.WithAutoUpdateTime(true)),
+	}})
+	if err == nil {
+		t.Error("autoUpdateTime of slice field should be rejected")
+	}
+}
@@ -0,0 +1,114 @@
+package tables
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zgljl2012/go-orm"
+	log "github.com/zgljl2012/slog"
+)
+
+// Update update all filtered rows with values in one statement, keys of values are field names,
+// ordering is ignored. The version field is increased and the auto update time fields are set to now
+// unless they are in values. Return the number of affected rows.
+func (f *filterSet) Update(values map[string]interface{}) (int64, error) {
+	t := f.table
+	if f.limit > 0 || f.offset > 0 {
+		return 0, fmt.Errorf(ErrSlicedFilterSet)
+	}
+	if len(values) == 0 {
+		return 0, nil
+	}
+	p := newParams(t.dialect)
+	// sort names to make the statement stable
+	names := []string{}
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sets := []string{}
+	for _, name := range names {
+		column, err := t.column(name)
+		if err != nil {
+			return 0, err
+		}
+		sets = append(sets, column+"="+p.add(values[name]))
+	}
+	// given report whether the field is set by the caller
+	given := func(field orm.Field) bool {
+		_, id := values[field.ID()]
+		_, name := values[field.Name()]
+		return id || name
+	}
+	if version := t.version(); version != nil && !given(version) {
+		column := t.quote(version.Name())
+		sets = append(sets, column+"="+column+"+1")
+	}
+	now := t.clock()
+	for _, field := range t.fields {
+		if field.AutoUpdateTime() && !given(field) {
+			sets = append(sets, t.quote(field.Name())+"="+p.add(now))
+		}
+	}
+	sql := "UPDATE " + t.quote(t.Name()) + " SET " + strings.Join(sets, ",")
+	where, err := f.bulkWhere(p)
+	if err != nil {
+		return 0, err
+	}
+	if where != "" {
+		sql += " WHERE " + where
+	}
+
+	log.Debug(sql)
+
+	return f.exec(sql, p)
+}
+
+// Delete delete all filtered rows in one statement, ordering is ignored.
+// The rows are marked deleted if the table has a soft delete field.
+// Return the number of affected rows.
+func (f *filterSet) Delete() (int64, error) {
+	t := f.table
+	if f.limit > 0 || f.offset > 0 {
+		return 0, fmt.Errorf(ErrSlicedFilterSet)
+	}
+	if field := t.softDelete(); field != nil {
+		return f.Update(map[string]interface{}{field.ID(): t.clock()})
+	}
+	p := newParams(t.dialect)
+	sql := "DELETE FROM " + t.quote(t.Name())
+	where, err := f.bulkWhere(p)
+	if err != nil {
+		return 0, err
+	}
+	if where != "" {
+		sql += " WHERE " + where
+	}
+
+	log.Debug(sql)
+
+	return f.exec(sql, p)
+}
+
+// exec execute the statement, return the number of affected rows
+func (f *filterSet) exec(sql string, p *params) (int64, error) {
+	result, err := f.table.exec(f.ctx, sql, p.values)
+	if err != nil {
+		log.Error("got an error when execute bulk operation", "err", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// bulkWhere render the conditions of bulk operations, which can't filter across relations
+func (f *filterSet) bulkWhere(p *params) (string, error) {
+	j, err := f.joins(nil)
+	if err != nil {
+		return "", err
+	}
+	if len(j.list) > 0 || crosses(f.conditions) {
+		return "", fmt.Errorf(ErrBulkJoin)
+	}
+	return f.where(p, j.column)
+}
@@ -0,0 +1,143 @@
+package tables_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/dialects"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+func TestBulkUpdateDelete(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Create(true); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 10; i++ {
+		if err := table.Add(&User{ID: i, Username: fmt.Sprintf("user%d", i), Active: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// update
+	affected, err := table.Filter(orm.WithParameter("ID__gt", 6)).Update(map[string]interface{}{
+		"Active":   false,
+		"password": "reset",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected != 4 {
+		t.Errorf("affected rows should be 4, but got %v", affected)
+	}
+	if cnt, err := table.Filter(orm.WithParameter("active", false), orm.WithParameter("password", "reset")).Count(); err != nil || cnt != 4 {
+		t.Errorf("expect 4, but got %v, err: %v", cnt, err)
+	}
+
+	// delete
+	affected, err = table.Filter(orm.WithParameter("active", false)).Delete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected != 4 {
+		t.Errorf("affected rows should be 4, but got %v", affected)
+	}
+	if cnt, err := table.Filter().Count(); err != nil || cnt != 6 {
+		t.Errorf("expect 6, but got %v, err: %v", cnt, err)
+	}
+
+	// errors
+	if _, err := table.Filter().Update(map[string]interface{}{"unknown": 1}); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if _, err := table.Filter().Limit(1).Delete(); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+
+	// check SQL
+	db = createRecordingDatabase()
+	table, err = tables.NewStructTagsTable(db, &User{}, tables.WithDialect(dialects.NewPostgres()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.Filter(orm.WithParameter("ID__in", []int{1, 2})).Update(map[string]interface{}{"username": "a", "active": true}); err != nil {
+		t.Fatal(err)
+	}
+	expect := `UPDATE "User" SET "active"=$1,"username"=$2 WHERE "id" IN ($3,$4)`
+	if sql, _ := rec.last(); sql != expect {
+		t.Errorf("expect %v\nbut got %v", expect, sql)
+	}
+}
+
+func TestAddMany(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Create(true); err != nil {
+		t.Fatal(err)
+	}
+
+	// more rows than the max parameters of SQLite
+	users := []User{}
+	for i := 1; i <= 1000; i++ {
+		users = append(users, User{ID: i, Username: fmt.Sprintf("user%d", i)})
+	}
+	if err := table.AddMany(users, 0); err != nil {
+		t.Fatal(err)
+	}
+	if cnt, err := table.Filter().Count(); err != nil || cnt != 1000 {
+		t.Errorf("expect 1000, but got %v, err: %v", cnt, err)
+	}
+
+	// pointers, and the whole batch is rolled back when a row fails
+	pointers := []*User{{ID: 1001}, {ID: 1002}, {ID: 1003}, {ID: 1}}
+	if err := table.AddMany(pointers, 3); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if cnt, err := table.Filter().Count(); err != nil || cnt != 1000 {
+		t.Errorf("expect 1000, but got %v, err: %v", cnt, err)
+	}
+
+	// wrong type
+	if err := table.AddMany(User{}, 0); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if err := table.AddMany([]int{1}, 0); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+
+	// check SQL
+	db = createRecordingDatabase()
+	table, err = tables.NewStructTagsTable(db, &User{}, tables.WithDialect(dialects.NewPostgres()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.AddMany(pointers[:3], 2); err != nil {
+		t.Fatal(err)
+	}
+	expect := `INSERT INTO "User" ("id","username","password","active","age","created_at","count") VALUES ($1,$2,$3,$4,$5,$6,$7)`
+	if len(rec.statements) != 2 {
+		t.Fatalf("should be 2 statements, but got %v", rec.statements)
+	}
+	if sql, args := rec.last(); sql != expect || len(args) != 7 {
+		t.Errorf("expect %v\nbut got %v", expect, sql)
+	}
+	if sql := rec.statements[0]; !strings.HasSuffix(sql, "($1,$2,$3,$4,$5,$6,$7),($8,$9,$10,$11,$12,$13,$14)") {
+		t.Errorf("the first batch should have 2 rows, but got %v", sql)
+	}
+}
@@ -0,0 +1,59 @@
+package tables_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+func TestWithContext(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Create(true); err != nil {
+		t.Fatal(err)
+	}
+
+	user := User{ID: 1, Username: "username", Password: "pwd"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := table.WithContext(ctx).Add(&user); err != nil {
+		t.Fatal(err)
+	}
+	if rows, err := table.Filter().WithContext(ctx).All(); err != nil {
+		t.Error(err)
+	} else if len(rows) != 1 {
+		t.Errorf("rows'cnt should be 1, but got %v", len(rows))
+	}
+
+	// canceled
+	cancel()
+	user.ID = 2
+	if err := table.WithContext(ctx).Add(&user); err != context.Canceled {
+		t.Errorf("expect %v, but got %v", context.Canceled, err)
+	}
+	if _, err := table.WithContext(ctx).Count(&user); err != context.Canceled {
+		t.Errorf("expect %v, but got %v", context.Canceled, err)
+	}
+	if _, err := table.Filter().WithContext(ctx).All(); err != context.Canceled {
+		t.Errorf("expect %v, but got %v", context.Canceled, err)
+	}
+
+	// the filter set itself is not affected
+	filter := table.Filter()
+	filter.WithContext(ctx)
+	if _, err := filter.All(); err != nil {
+		t.Error(err)
+	}
+
+	// the table itself is not affected
+	if err := table.Add(&user); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,52 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/zgljl2012/go-orm"
+)
+
+// cursor scans the rows of a filter set one by one
+type cursor struct {
+	filter *filterSet
+	rows   *sql.Rows
+}
+
+// Iter return a cursor over the rows, remember to close it
+func (f *filterSet) Iter() (orm.Cursor, error) {
+	t := f.table
+	query, p, err := f.selectSQL()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := t.executor().QueryContext(f.ctx, query, p.values...)
+	if err != nil {
+		return nil, err
+	}
+	return &cursor{
+		filter: f,
+		rows:   rows,
+	}, nil
+}
+
+func (c *cursor) Next() bool {
+	return c.rows.Next()
+}
+
+// Scan copy the current row into dst, dst should be a pointer of the table struct
+func (c *cursor) Scan(dst interface{}) error {
+	if reflect.TypeOf(dst) != reflect.TypeOf(c.filter.table.table) {
+		return fmt.Errorf(ErrDestinationType)
+	}
+	return c.rows.Scan(c.filter.scanDest(reflect.ValueOf(dst).Elem())...)
+}
+
+func (c *cursor) Err() error {
+	return c.rows.Err()
+}
+
+func (c *cursor) Close() error {
+	return c.rows.Close()
+}
@@ -0,0 +1,67 @@
+package tables_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+func TestIter(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Create(true); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 10; i++ {
+		if err := table.Add(&User{ID: i, Username: fmt.Sprintf("username%d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cursor, err := table.Filter().OrderBy("ID").Iter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cursor.Close()
+
+	// scan into a reused struct
+	user := User{}
+	id := 0
+	for cursor.Next() {
+		id++
+		if err := cursor.Scan(&user); err != nil {
+			t.Fatal(err)
+		}
+		if user.ID != id || user.Username != fmt.Sprintf("username%d", id) {
+			t.Errorf("expect user %v, but got %v %v", id, user.ID, user.Username)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		t.Error(err)
+	}
+	if id != 10 {
+		t.Errorf("should iterate 10 rows, but got %v", id)
+	}
+
+	// wrong destination
+	cursor, err = table.Filter(orm.WithParameter("ID", 1)).Iter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cursor.Close()
+	if !cursor.Next() {
+		t.Fatal("should have one row")
+	}
+	if err := cursor.Scan(user); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+}
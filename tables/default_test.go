@@ -0,0 +1,115 @@
+package tables_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/fields"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+// Setting is a test table with default values
+type Setting struct {
+	ID        int       `name:"id" primaryKey:"true"`
+	Key       string    `name:"key" length:"20" default:"it's"`
+	Enabled   bool      `name:"enabled" default:"true"`
+	Priority  int       `name:"priority" default:"5"`
+	CreatedAt time.Time `name:"created_at" default:"CURRENT_TIMESTAMP"`
+}
+
+// Token is a test table declared with fields
+type Token struct {
+	ID    int
+	Value string
+}
+
+func TestDefault(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &Setting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Create(false); err != nil {
+		t.Fatal(err)
+	}
+
+	// the database applies the defaults to the zero fields
+	if err := table.Add(&Setting{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	got := Setting{}
+	if err := table.Filter(orm.WithParameter("ID", 1)).Get(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Key != "it's" || !got.Enabled || got.Priority != 5 || got.CreatedAt.IsZero() {
+		t.Errorf("defaults are wrong: %+v", got)
+	}
+
+	// non-zero values are added as they are
+	if err := table.Add(&Setting{ID: 2, Key: "key", Priority: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Filter(orm.WithParameter("ID", 2)).Get(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Key != "key" || !got.Enabled || got.Priority != 1 {
+		t.Errorf("values are wrong: %+v", got)
+	}
+
+	// batch rows which omit different columns are inserted separately
+	if err := table.AddMany([]Setting{{ID: 3}, {ID: 4, Priority: 2}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Filter(orm.WithParameter("ID", 4)).Get(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Key != "it's" || got.Priority != 2 {
+		t.Errorf("values of batch are wrong: %+v", got)
+	}
+}
+
+func TestDefaultFunc(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	n := 0
+	table, err := tables.NewTable(db, &fieldsTable{fields: []orm.Field{
+		fields.NewIntField("ID", fields.WithPrimaryKey(true)),
+		fields.NewCharField("Value", fields.WithDefaultFunc(func() interface{} {
+			n++
+			return "token-" + strings.Repeat("x", n)
+		})),
+	}}, tables.WithName("Token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Create(false); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := []Token{{ID: 1}, {ID: 2}, {ID: 3, Value: "given"}}
+	for i := range tokens {
+		if err := table.Add(&tokens[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tokens[0].Value != "token-x" || tokens[1].Value != "token-xx" || tokens[2].Value != "given" {
+		t.Errorf("values are wrong: %+v", tokens)
+	}
+}
+
+func TestDefaultTagError(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	_, err := tables.NewStructTagsTable(db, &struct {
+		ID int `name:"id" primaryKey:"true" default:"one"`
+	}{})
+	if err == nil {
+		t.Error("invalid default should be rejected")
+	}
+}
@@ -0,0 +1,143 @@
+package tables_test
+
+import (
+	"database/sql/driver"
+	"math"
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/dialects"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+func TestMySQLDialect(t *testing.T) {
+	db := createRecordingDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{}, tables.WithDialect(dialects.NewMySQL()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := User{ID: 1, Username: "username", Password: "pwd"}
+
+	cases := []struct {
+		name   string
+		action func() error
+		expect string
+	}{
+		{
+			name:   "create",
+			action: func() error { return table.Create(true) },
+			expect: "CREATE TABLE IF NOT EXISTS `User`(`id` INT NOT NULL,`username` CHAR(20) NULL,`password` CHAR(50) NULL," +
+				"`active` TINYINT(1) NOT NULL,`age` FLOAT NULL,`created_at` DATETIME(6) NULL,`count` BIGINT UNSIGNED NULL, PRIMARY KEY(`id`))",
+		},
+		{
+			name:   "add",
+			action: func() error { return table.Add(&user) },
+			expect: "INSERT INTO `User` (`id`,`username`,`password`,`active`,`age`,`created_at`,`count`) VALUES (?,?,?,?,?,?,?)",
+		},
+		{
+			name:   "update",
+			action: func() error { return table.Update(&user) },
+			expect: "UPDATE `User` SET `id`=?,`username`=?,`password`=?,`active`=?,`age`=?,`created_at`=?,`count`=? WHERE `id`=?",
+		},
+		{
+			name:   "upsert",
+			action: func() error { return table.Upsert(&user) },
+			expect: "INSERT INTO `User` (`id`,`username`,`password`,`active`,`age`,`created_at`,`count`) VALUES (?,?,?,?,?,?,?) " +
+				"ON DUPLICATE KEY UPDATE `username`=VALUES(`username`),`password`=VALUES(`password`),`active`=VALUES(`active`)," +
+				"`age`=VALUES(`age`),`created_at`=VALUES(`created_at`),`count`=VALUES(`count`)",
+		},
+		{
+			name: "filter",
+			action: func() error {
+				_, err := table.Filter(orm.WithParameter("ID", 1)).OrderBy("-Username").Offset(2).All()
+				return err
+			},
+			expect: "SELECT `id`,`username`,`password`,`active`,`age`,`created_at`,`count` FROM `User` WHERE `id` = ? ORDER BY `username` DESC LIMIT 18446744073709551615 OFFSET 2",
+		},
+		{
+			name:   "truncate",
+			action: func() error { return table.Truncate() },
+			expect: "TRUNCATE TABLE `User`",
+		},
+		{
+			name: "exists",
+			action: func() error {
+				_, err := table.TableExists()
+				return err
+			},
+			expect: "SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?",
+		},
+	}
+
+	for _, c := range cases {
+		if err := c.action(); err != nil {
+			t.Errorf("%v: %v", c.name, err)
+			continue
+		}
+		if sql, _ := rec.last(); sql != c.expect {
+			t.Errorf("%v:\nexpect %v\nbut got %v", c.name, c.expect, sql)
+		}
+	}
+}
+
+func TestPostgresDialect(t *testing.T) {
+	db := createRecordingDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{}, tables.WithDialect(dialects.NewPostgres()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	expect := `CREATE TABLE "User"("id" INTEGER NOT NULL,"username" VARCHAR(20) NULL,"password" VARCHAR(50) NULL,` +
+		`"active" BOOLEAN NOT NULL,"age" REAL NULL,"created_at" TIMESTAMPTZ NULL,"count" BIGINT NULL, PRIMARY KEY("id"))`
+	if sql, _ := rec.last(); sql != expect {
+		t.Errorf("expect %v\nbut got %v", expect, sql)
+	}
+
+	user := User{ID: 1, Username: "username", Password: "pwd"}
+	if err := table.Update(&user); err != nil {
+		t.Fatal(err)
+	}
+	expect = `UPDATE "User" SET "id"=$1,"username"=$2,"password"=$3,"active"=$4,"age"=$5,"created_at"=$6,"count"=$7 WHERE "id"=$8`
+	if sql, args := rec.last(); sql != expect {
+		t.Errorf("expect %v\nbut got %v", expect, sql)
+	} else if len(args) != 8 || args[7] != int64(1) {
+		t.Errorf("arguments are wrong: %v", args)
+	}
+
+	if err := table.Truncate(); err != nil {
+		t.Fatal(err)
+	}
+	expect = `TRUNCATE TABLE "User" RESTART IDENTITY`
+	if sql, _ := rec.last(); sql != expect {
+		t.Errorf("expect %v\nbut got %v", expect, sql)
+	}
+}
+
+func TestScanUint64(t *testing.T) {
+	db := createRecordingDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{}, tables.WithDialect(dialects.NewMySQL()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// MySQL returns BIGINT UNSIGNED as text
+	rec.returns([]driver.Value{int64(1), []byte("username"), nil, int64(1), nil, nil, []byte("18446744073709551615")})
+	rows, err := table.Filter().All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].(User).Count != math.MaxUint64 {
+		t.Errorf("count should be %d, but got %v", uint64(math.MaxUint64), rows)
+	}
+
+	rec.returns([]driver.Value{int64(1), nil, nil, int64(1), nil, nil, int64(-1)})
+	if _, err := table.Filter().All(); err == nil {
+		t.Error("negative value should not be scanned into uint64")
+	}
+}
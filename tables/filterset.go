@@ -1,8 +1,7 @@
 package tables
 
 import (
-	"database/sql"
-	"fmt"
+	"context"
 	"reflect"
 	"strings"
 
@@ -10,32 +9,43 @@ import (
 	log "github.com/zgljl2012/slog"
 )
 
+// scope render a condition which always applies to the filter set, e.g. the rows related to an instance
+type scope func(p *params, resolve resolver) (string, error)
+
 type filterSet struct {
-	instance   interface{}
-	table      string
+	ctx        context.Context
+	table      *simpleTable
 	offset     int
 	limit      int
-	parameters []*orm.QueryParameter
+	conditions []orm.Condition
 	order      []string
-	db         *sql.DB
-	fields     []orm.Field
+	preload    []string
+	scopes     []scope
+	deleted    int   // visibility of the soft deleted rows
+	err        error // the error when building the filter set, it's returned by the queries
 }
 
-func newFilterSet(db *sql.DB, table string, instance interface{}, fields []orm.Field) orm.FilterSet {
+func newFilterSet(table *simpleTable) orm.FilterSet {
 	return &filterSet{
-		instance:   instance,
-		db:         db,
+		ctx:        table.ctx,
 		table:      table,
 		limit:      0,
 		offset:     0,
-		parameters: []*orm.QueryParameter{},
-		fields:     fields,
+		conditions: []orm.Condition{},
 	}
 }
 
-// Filter with paramters
-func (f *filterSet) Filter(parameters ...*orm.QueryParameter) orm.FilterSet {
-	f.parameters = append(f.parameters, parameters...)
+// Filter with conditions
+func (f *filterSet) Filter(conditions ...orm.Condition) orm.FilterSet {
+	f.conditions = append(f.conditions, conditions...)
+	return f
+}
+
+// Exclude rows which match all the conditions
+func (f *filterSet) Exclude(conditions ...orm.Condition) orm.FilterSet {
+	if len(conditions) > 0 {
+		f.conditions = append(f.conditions, orm.Not(conditions...))
+	}
 	return f
 }
 
@@ -53,87 +63,138 @@ func (f *filterSet) Limit(limit int) orm.FilterSet {
 	return f
 }
 
-// All return all rows
-func (f *filterSet) All() []interface{} {
+// where render the scopes and conditions of this filter set joined with AND
+func (f *filterSet) where(p *params, resolve resolver) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	conditions := []string{}
+	if sql, err := f.deletedScope(resolve); err != nil {
+		return "", err
+	} else if sql != "" {
+		conditions = append(conditions, sql)
+	}
+	for _, s := range f.scopes {
+		sql, err := s(p, resolve)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, sql)
+	}
+	render := func(parameter *orm.QueryParameter) (string, error) {
+		if strings.Contains(parameter.Name, "__") {
+			return f.exists(parameter, p)
+		}
+		column, err := resolve(parameter.Name)
+		if err != nil {
+			return "", err
+		}
+		return lookup(parameter, column, p)
+	}
+	for _, condition := range f.conditions {
+		sql, err := f.table.render(condition, render)
+		if err != nil {
+			return "", err
+		}
+		if sql != "" {
+			conditions = append(conditions, sql)
+		}
+	}
+	return strings.Join(conditions, " AND "), nil
+}
+
+// selectSQL build the SELECT statement of this filter set
+func (f *filterSet) selectSQL() (string, *params, error) {
 	var (
-		sql    string
-		names  []string
-		values []interface{}
+		sql string
+		t   = f.table
+		p   = newParams(t.dialect)
 	)
+	j, err := f.joins(f.order)
+	if err != nil {
+		return "", nil, err
+	}
 	// filter
-	sql = "SELECT * FROM " + f.table
-	if len(f.parameters) > 0 {
-		sql += " WHERE "
-		for _, parameter := range f.parameters {
-			names = append(names, parameter.Name+" "+parameter.Operator+" ?")
-			values = append(values, parameter.Value)
-		}
-		sql += strings.Join(names, ",")
+	sql = "SELECT " + j.columns() + " FROM " + j.from()
+	where, err := f.where(p, j.column)
+	if err != nil {
+		return "", nil, err
+	}
+	if where != "" {
+		sql += " WHERE " + where
 	}
 	// order
 	var orders []string
 	for _, order := range f.order {
 		order = strings.Trim(order, " ")
-		if order[0] == '-' {
-			orders = append(orders, order[1:]+" DESC")
-		} else {
-			orders = append(orders, order)
+		column, err := j.column(strings.TrimPrefix(order, "-"))
+		if err != nil {
+			return "", nil, err
 		}
+		if strings.HasPrefix(order, "-") {
+			column += " DESC"
+		}
+		orders = append(orders, column)
 	}
 	if len(orders) > 0 {
 		sql += " ORDER BY " + strings.Join(orders, ",")
 	}
-	// limit
-	if f.limit > 0 {
-		sql += fmt.Sprintf(" LIMIT %v", f.limit)
+	// limit, offset
+	if clause := t.dialect.LimitOffset(f.limit, f.offset); clause != "" {
+		sql += " " + clause
 	}
-	// offset
-	if f.offset > 0 {
-		if f.limit <= 0 {
-			// default limit
-			sql += fmt.Sprintf(" LIMIT %v", 10)
-		}
-		sql += fmt.Sprintf(" OFFSET %v", f.offset)
+	return sql, p, nil
+}
+
+// scanDest return the destinations of the fields of obj in the order of columns
+func (f *filterSet) scanDest(obj reflect.Value) []interface{} {
+	fields := f.table.fields
+	columns := make([]interface{}, len(fields))
+	for i, field := range fields {
+		columns[i] = &nullable{dst: obj.FieldByName(field.ID())}
 	}
-	// query
-	log.Debug(sql)
-	tx, err := f.db.Begin()
+	return columns
+}
+
+// All return all rows
+func (f *filterSet) All() ([]interface{}, error) {
+	t := f.table
+	sql, p, err := f.selectSQL()
 	if err != nil {
-		log.Fatal("get tx error when iterate all rows", "err", err)
+		return nil, err
 	}
-	stmt, err := tx.Prepare(sql)
+	// query
+	log.Debug(sql)
+	objs := []reflect.Value{}
+	err = t.query(f.ctx, sql, p.values, func(row scanner) error {
+		// new instance
+		obj := reflect.New(reflect.TypeOf(t.table).Elem()).Elem()
+		if err := row.Scan(f.scanDest(obj)...); err != nil {
+			return err
+		}
+		objs = append(objs, obj)
+		return nil
+	})
 	if err != nil {
-		log.Fatal("stmt error when iterate all rows", "err", err)
-	}
-	result := []interface{}{}
-	if rows, err := stmt.Query(values...); err != nil {
 		log.Error("iterate data error", "err", err)
-	} else {
-		for rows.Next() {
-			// new instance
-			obj := reflect.New(reflect.TypeOf(f.instance).Elem()).Elem()
-			numCols := len(f.fields)
-			columns := make([]interface{}, numCols)
-			for i := 0; i < numCols; i++ {
-				field := obj.FieldByName(f.fields[i].ID())
-				columns[i] = field.Addr().Interface()
-			}
-			if err := rows.Scan(columns...); err != nil {
-				log.Info("scan row error", "err", err)
-			}
-			result = append(result, obj.Interface())
-		}
-		if err := rows.Close(); err != nil {
-			log.Error("got an error when close rows", "err", err)
-		}
+		return nil, err
 	}
-	if err := tx.Commit(); err != nil {
-		log.Error(err)
+	// relations are loaded after the rows are closed
+	if err := f.preloadRelations(objs); err != nil {
+		return nil, err
 	}
-	if err := stmt.Close(); err != nil {
-		log.Error(err)
+	result := make([]interface{}, len(objs))
+	for i, obj := range objs {
+		result[i] = obj.Interface()
 	}
-	return result
+	return result, nil
+}
+
+// WithContext return a copy of the filter set which runs the query with ctx
+func (f *filterSet) WithContext(ctx context.Context) orm.FilterSet {
+	c := f.clone()
+	c.ctx = ctx
+	return c
 }
 
 func (f *filterSet) Offset(offset int) orm.FilterSet {
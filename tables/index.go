@@ -0,0 +1,82 @@
+package tables
+
+import (
+	"github.com/zgljl2012/go-orm"
+)
+
+// indexes collect the indexes of fields, indexes with the same name are merged into a composite index
+// whose columns are in order of fields. Unique fields get an unique index named <table>_<column>_key.
+func (t *simpleTable) indexes() []orm.Index {
+	indexes := []orm.Index{}
+	positions := map[string]int{}
+	for _, field := range t.fields {
+		if field.Unique() {
+			indexes = append(indexes, orm.Index{
+				Name:    t.Name() + "_" + field.Name() + "_key",
+				Columns: []string{field.Name()},
+				Unique:  true,
+			})
+		}
+		for _, index := range field.Indexes() {
+			i, ok := positions[index.Name]
+			if !ok {
+				i = len(indexes)
+				positions[index.Name] = i
+				indexes = append(indexes, orm.Index{Name: index.Name})
+			}
+			merged := &indexes[i]
+			merged.Columns = append(merged.Columns, field.Name())
+			merged.Unique = merged.Unique || index.Unique
+			if index.Where != "" {
+				merged.Where = index.Where
+			}
+		}
+	}
+	return indexes
+}
+
+// liveIndexes list the names of the indexes of the table in database
+func (t *simpleTable) liveIndexes(tx executor) (map[string]bool, error) {
+	query, values := t.dialect.IndexesQuery(t.Name())
+	rows, err := tx.QueryContext(t.ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	names := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names[name] = true
+	}
+	return names, rows.Err()
+}
+
+// createIndexes create the indexes which don't exist, return their names
+func (t *simpleTable) createIndexes(tx executor) ([]string, error) {
+	indexes := t.indexes()
+	if len(indexes) == 0 {
+		return nil, nil
+	}
+	live, err := t.liveIndexes(tx)
+	if err != nil {
+		return nil, err
+	}
+	created := []string{}
+	for _, index := range indexes {
+		if live[index.Name] {
+			continue
+		}
+		sql, err := t.dialect.CreateIndex(t.Name(), index)
+		if err != nil {
+			return created, err
+		}
+		if err := t.execDDL(tx, sql); err != nil {
+			return created, err
+		}
+		created = append(created, index.Name)
+	}
+	return created, nil
+}
@@ -0,0 +1,165 @@
+package tables_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/dialects"
+	"github.com/zgljl2012/go-orm/fields"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+// Account is a test table with indexes
+type Account struct {
+	ID        int    `name:"id" primaryKey:"true"`
+	Email     string `name:"email" length:"50" unique:"true"`
+	FirstName string `name:"first_name" length:"20" index:"idx_name"`
+	LastName  string `name:"last_name" length:"20" index:"idx_name"`
+	Nickname  string `name:"nickname" length:"20" index:"idx_nickname,unique,where:active = 1"`
+	Active    bool   `name:"active" null:"false"`
+}
+
+func TestIndexes(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &Account{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	// wrong index tags
+	wrongs := []interface{}{
+		&struct {
+			ID int `name:"id" primaryKey:"true" index:",unique"`
+		}{},
+		&struct {
+			ID int `name:"id" primaryKey:"true" index:"idx_id,primary"`
+		}{},
+		&struct {
+			ID int `name:"id" primaryKey:"true" index:"idx_id,where:"`
+		}{},
+		&struct {
+			ID int `name:"id" primaryKey:"true" unique:"yes"`
+		}{},
+	}
+	for _, wrong := range wrongs {
+		if _, err := tables.NewStructTagsTable(db, wrong); err == nil {
+			t.Errorf("should got an error, but is normal: %T", wrong)
+		}
+	}
+	// skip existing indexes
+	if err := table.Create(true); err != nil {
+		t.Fatal(err)
+	}
+
+	expects := map[string]string{
+		"Account_email_key": `CREATE UNIQUE INDEX "Account_email_key" ON "Account" ("email")`,
+		"idx_name":          `CREATE INDEX "idx_name" ON "Account" ("first_name","last_name")`,
+		"idx_nickname":      `CREATE UNIQUE INDEX "idx_nickname" ON "Account" ("nickname") WHERE active = 1`,
+	}
+	for name, expect := range expects {
+		var sql string
+		if err := db.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'index' AND name = ?`, name).Scan(&sql); err != nil {
+			t.Errorf("%v: %v", name, err)
+			continue
+		}
+		if sql != expect {
+			t.Errorf("%v:\nexpect %v\nbut got %v", name, expect, sql)
+		}
+	}
+
+	// unique
+	if err := table.Add(&Account{ID: 1, Email: "a@example.com", Nickname: "a", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Add(&Account{ID: 2, Email: "a@example.com"}); err == nil {
+		t.Error("email should be unique")
+	}
+	// partial
+	if err := table.Add(&Account{ID: 2, Email: "b@example.com", Nickname: "a", Active: false}); err != nil {
+		t.Errorf("inactive nickname should not be unique: %v", err)
+	}
+	if err := table.Add(&Account{ID: 3, Email: "c@example.com", Nickname: "a", Active: true}); err == nil {
+		t.Error("active nickname should be unique")
+	}
+}
+
+func TestMigrateIndexes(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	if _, err := db.Exec(`CREATE TABLE "Account"("id" INT NOT NULL, "email" INT NULL, "first_name" CHAR(20) NULL,
+		"last_name" CHAR(20) NULL, "nickname" CHAR(20) NULL, "active" BOOL NOT NULL, PRIMARY KEY("id"))`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE INDEX "idx_name" ON "Account" ("first_name","last_name")`); err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := tables.NewStructTagsTable(db, &Account{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := table.Migrate(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(report.Indexes) != "[Account_email_key idx_nickname]" {
+		t.Errorf("created indexes are wrong: %v", report.Indexes)
+	}
+
+	// the indexes are recreated after rebuilding
+	report, err = table.Migrate(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Rebuilt || fmt.Sprint(report.Indexes) != "[Account_email_key idx_name idx_nickname]" {
+		t.Errorf("indexes should be recreated: %+v", report)
+	}
+}
+
+func TestIndexesSQL(t *testing.T) {
+	index := fields.WithIndex(orm.Index{Name: "idx_name", Unique: true, Where: `"active"`})
+	cases := []struct {
+		dialect orm.Dialect
+		expect  string
+	}{
+		{dialects.NewPostgres(), `CREATE UNIQUE INDEX "idx_name" ON "Account" ("name") WHERE "active"`},
+		{dialects.NewMySQL(), ""},
+	}
+	for _, c := range cases {
+		db := createRecordingDatabase()
+		table, err := tables.NewTable(db, &fieldsTable{fields: []orm.Field{
+			fields.NewIntField("id", fields.WithPrimaryKey(true)),
+			fields.NewCharField("name", index),
+		}}, tables.WithDialect(c.dialect), tables.WithName("Account"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = table.Create(false)
+		if c.expect == "" {
+			if err == nil {
+				t.Errorf("%v: partial index should not be supported", c.dialect.Name())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", c.dialect.Name(), err)
+		} else if sql, _ := rec.last(); sql != c.expect {
+			t.Errorf("%v:\nexpect %v\nbut got %v", c.dialect.Name(), c.expect, sql)
+		}
+	}
+}
+
+// fieldsTable is a table declared with fields
+type fieldsTable struct {
+	fields []orm.Field
+}
+
+func (t *fieldsTable) Fields() []orm.Field {
+	return t.fields
+}
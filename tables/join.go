@@ -0,0 +1,198 @@
+package tables
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/zgljl2012/go-orm"
+)
+
+// join a table joined by a relation path, e.g. author, or posts__author across two relations
+type join struct {
+	table   *simpleTable
+	clauses []string // JOIN clauses without the join type, many-to-many relations need two
+}
+
+// joins the tables joined by the lookups across relations, e.g. author__name.
+// The joined tables are aliased by their paths, and they are all LEFT JOIN.
+type joins struct {
+	root    *simpleTable
+	alias   string // alias of root table
+	list    []*join
+	aliases map[string]*join
+	scoped  bool // soft deleted rows of the joined tables are excluded
+}
+
+func newJoins(root *simpleTable, alias string) *joins {
+	return &joins{root: root, alias: alias, aliases: map[string]*join{}}
+}
+
+// joins collect the tables joined by the names of filter set, e.g. ordering and grouping fields.
+// Conditions across relations don't join tables, they are rendered by exists.
+func (f *filterSet) joins(names []string) (*joins, error) {
+	j := newJoins(f.table, f.table.Name())
+	for _, name := range names {
+		if _, err := j.resolve(strings.TrimPrefix(strings.Trim(name, " "), "-")); err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// exists render the query parameter across relations as an EXISTS subquery correlated by primary keys,
+// so that rows aren't repeated by to-many relations, and rows without related rows aren't dropped by OR and NOT.
+// Soft deleted related rows are treated as missing.
+func (f *filterSet) exists(parameter *orm.QueryParameter, p *params) (string, error) {
+	t := f.table
+	keys := t.primaryKeys()
+	if len(keys) == 0 {
+		return "", fmt.Errorf(ErrPrimaryKeyNotExists)
+	}
+	sub := newJoins(t, "__"+t.Name())
+	sub.scoped = true
+	column, err := sub.resolve(parameter.Name)
+	if err != nil {
+		return "", err
+	}
+	conditions := []string{}
+	for _, key := range keys {
+		conditions = append(conditions, t.quote(sub.alias)+"."+t.quote(key)+" = "+t.quote(t.Name())+"."+t.quote(key))
+	}
+	sql, err := lookup(parameter, column, p)
+	if err != nil {
+		return "", err
+	}
+	conditions = append(conditions, sql)
+	return "EXISTS (SELECT 1 FROM " + sub.from() + " WHERE " + strings.Join(conditions, " AND ") + ")", nil
+}
+
+// crosses report whether any query parameter of the conditions looks up across relations
+func crosses(conditions []orm.Condition) bool {
+	for _, condition := range conditions {
+		switch c := condition.(type) {
+		case *orm.QueryParameter:
+			if strings.Contains(c.Name, "__") {
+				return true
+			}
+		case *orm.Group:
+			if crosses(c.Conditions) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolve return the column of name qualified by its table, the tables on the path are joined
+func (j *joins) resolve(name string) (string, error) {
+	segments := strings.Split(name, "__")
+	t, alias := j.root, j.alias
+	for i := 0; i < len(segments)-1; i++ {
+		path := strings.Join(segments[:i+1], "__")
+		joined, ok := j.aliases[path]
+		if !ok {
+			var err error
+			if joined, err = t.join(alias, segments[i], path); err != nil {
+				return "", err
+			}
+			if field := joined.table.softDelete(); field != nil && j.scoped {
+				last := len(joined.clauses) - 1
+				joined.clauses[last] += " AND " + j.root.quote(path) + "." + j.root.quote(field.Name()) + " IS NULL"
+			}
+			j.aliases[path] = joined
+			j.list = append(j.list, joined)
+		}
+		t, alias = joined.table, path
+	}
+	field, err := t.field(segments[len(segments)-1])
+	if err != nil {
+		return "", err
+	}
+	return j.root.quote(alias) + "." + j.root.quote(field.Name()), nil
+}
+
+// column is the resolver of filter set, columns are qualified only if there are joins
+func (j *joins) column(name string) (string, error) {
+	if len(j.list) == 0 {
+		return j.root.column(name)
+	}
+	return j.resolve(name)
+}
+
+// columns return the columns of root table
+func (j *joins) columns() string {
+	if len(j.list) == 0 {
+		return j.root.columns()
+	}
+	columns := []string{}
+	for _, field := range j.root.fields {
+		columns = append(columns, j.root.quote(j.alias)+"."+j.root.quote(field.Name()))
+	}
+	return strings.Join(columns, ",")
+}
+
+// from render the root table with joins
+func (j *joins) from() string {
+	sql := j.root.quote(j.root.Name())
+	if j.alias != j.root.Name() {
+		sql += " AS " + j.root.quote(j.alias)
+	}
+	for _, joined := range j.list {
+		for _, clause := range joined.clauses {
+			sql += " LEFT JOIN " + clause
+		}
+	}
+	return sql
+}
+
+// relationField find the relation field by name case-insensitively, e.g. author for Author
+func (t *simpleTable) relationField(name string) (string, bool) {
+	typ := reflect.TypeOf(t.table).Elem()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if _, ok := field.Tag.Lookup("name"); !ok && strings.EqualFold(field.Name, name) {
+			return field.Name, true
+		}
+	}
+	return "", false
+}
+
+// join the table of relation named name to t aliased alias, the joined table is aliased path
+func (t *simpleTable) join(alias, name, path string) (*join, error) {
+	field, ok := t.relationField(name)
+	if !ok {
+		return nil, fmt.Errorf(`%s: "%s"`, ErrFieldNotExists, path)
+	}
+	q := t.quote
+	if _, ok := t.relationTag(field, "m2m"); ok {
+		m, err := t.manyToMany(field)
+		if err != nil {
+			return nil, err
+		}
+		through := path + "__" + m.join
+		return &join{
+			table: m.target,
+			clauses: []string{
+				q(m.join) + " AS " + q(through) + " ON " + q(through) + "." + q(m.ownerColumn) + " = " + q(alias) + "." + q(m.ownerKey.Name()),
+				q(m.target.Name()) + " AS " + q(path) + " ON " + q(path) + "." + q(m.targetKey.Name()) + " = " + q(through) + "." + q(m.targetColumn),
+			},
+		}, nil
+	}
+	r, err := t.relation(field)
+	if err != nil {
+		return nil, err
+	}
+	return &join{
+		table: r.target,
+		clauses: []string{
+			q(r.target.Name()) + " AS " + q(path) + " ON " + q(path) + "." + q(r.remote.Name()) + " = " + q(alias) + "." + q(r.local.Name()),
+		},
+	}, nil
+}
+
+// relationTag return the tag of relation field
+func (t *simpleTable) relationTag(field, tag string) (string, bool) {
+	f, _ := reflect.TypeOf(t.table).Elem().FieldByName(field)
+	return f.Tag.Lookup(tag)
+}
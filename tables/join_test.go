@@ -0,0 +1,257 @@
+package tables_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/dialects"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+func titles(t *testing.T, rows []interface{}, err error) string {
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := []string{}
+	for _, row := range rows {
+		result = append(result, row.(Post).Title)
+	}
+	return fmt.Sprint(result)
+}
+
+func TestJoinLookups(t *testing.T) {
+	defer deleteTestDatabase()
+	db, authors, posts := createRelationTables(t)
+
+	cases := []struct {
+		name   string
+		filter orm.FilterSet
+		expect string
+	}{
+		{
+			name:   "belongs to",
+			filter: posts.Filter(orm.WithParameter("author__name", "bob")),
+			expect: "[b1]",
+		},
+		{
+			name:   "lookup",
+			filter: posts.Filter(orm.WithParameter("author__name__in", []string{"alice", "carol"})).OrderBy("-id"),
+			expect: "[a2 a1]",
+		},
+		{
+			name:   "order",
+			filter: posts.Filter().OrderBy("-author__name", "id"),
+			expect: "[b1 a1 a2]",
+		},
+		{
+			name:   "across two relations",
+			filter: posts.Filter(orm.WithParameter("author__posts__title", "a2")).OrderBy("id"),
+			expect: "[a1 a2]",
+		},
+		{
+			name:   "exclude",
+			filter: posts.Filter().Exclude(orm.WithParameter("author__name", "alice")),
+			expect: "[b1]",
+		},
+	}
+	for _, c := range cases {
+		rows, err := c.filter.All()
+		if got := titles(t, rows, err); got != c.expect {
+			t.Errorf("%v: expect %v, but got %v", c.name, c.expect, got)
+		}
+	}
+
+	// has many
+	author := Author{}
+	if err := authors.Filter(orm.WithParameter("posts__title", "b1")).Get(&author); err != nil || author.Name != "bob" {
+		t.Errorf("author of b1 should be bob, but got %v: %v", author.Name, err)
+	}
+	if count, err := authors.Filter(orm.WithParameter("posts__title__startswith", "b")).Count(); err != nil || count != 1 {
+		t.Errorf("expect 1 author, but got %d: %v", count, err)
+	}
+	if exists, err := authors.Filter(orm.WithParameter("posts__title", "c1")).Exists(); err != nil || exists {
+		t.Errorf("author of c1 should not exist: %v", err)
+	}
+
+	// an author with many matched posts is returned once
+	rows, err := authors.Filter(orm.WithParameter("posts__title__startswith", "a")).All()
+	if err != nil || len(rows) != 1 {
+		t.Errorf("expect 1 author, but got %v: %v", rows, err)
+	}
+	if count, err := authors.Filter(orm.WithParameter("posts__title__startswith", "a")).Count(); err != nil || count != 1 {
+		t.Errorf("expect 1 author, but got %d: %v", count, err)
+	}
+	if err := authors.Filter(orm.WithParameter("posts__title__startswith", "a")).Get(&author); err != nil || author.Name != "alice" {
+		t.Errorf("author of a1 and a2 should be alice, but got %v: %v", author.Name, err)
+	}
+
+	// a post without author still matches OR and NOT
+	if _, err := db.Exec(`INSERT INTO "Post" ("id","author_id","title") VALUES (4, NULL, 'orphan')`); err != nil {
+		t.Fatal(err)
+	}
+	rows, err = posts.Filter(orm.Or(
+		orm.WithParameter("author__name", "alice"), orm.WithParameter("title", "orphan"),
+	)).OrderBy("id").All()
+	if got := titles(t, rows, err); got != "[a1 a2 orphan]" {
+		t.Errorf("or: expect [a1 a2 orphan], but got %v", got)
+	}
+	rows, err = posts.Filter().Exclude(orm.WithParameter("author__name", "alice")).OrderBy("id").All()
+	if got := titles(t, rows, err); got != "[b1 orphan]" {
+		t.Errorf("exclude: expect [b1 orphan], but got %v", got)
+	}
+	rows, err = posts.Filter(orm.WithParameter("author__id__isnull", true)).All()
+	if got := titles(t, rows, err); got != "[orphan]" {
+		t.Errorf("isnull: expect [orphan], but got %v", got)
+	}
+	if _, err := db.Exec(`DELETE FROM "Post" WHERE "id" = 4`); err != nil {
+		t.Fatal(err)
+	}
+
+	// aggregate
+	groups, err := posts.Filter().GroupBy("author__name").OrderBy("author__name").Aggregate(orm.Count("*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(groups) != "[map[author__name:alice count:2] map[author__name:bob count:1]]" {
+		t.Errorf("aggregation is wrong: %v", groups)
+	}
+
+	// bulk operations
+	if _, err := posts.Filter(orm.WithParameter("author__name", "bob")).Delete(); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if _, err := posts.Filter(orm.WithParameter("writer__name", "bob")).All(); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+}
+
+func TestJoinManyToMany(t *testing.T) {
+	defer deleteTestDatabase()
+	db := createTestDatabase()
+	members, err := tables.NewStructTagsTable(db, &Member{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups, err := tables.NewStructTagsTable(db, &Group{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := members.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := groups.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	alice, bob := Member{ID: 1, Name: "alice"}, Member{ID: 2, Name: "bob"}
+	if err := members.AddMany([]Member{alice, bob}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := groups.AddMany([]Group{{ID: 1, Name: "admin"}, {ID: 2, Name: "dev"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := groups.Associate(&Group{ID: 2}, "Members", &alice, &bob); err != nil {
+		t.Fatal(err)
+	}
+	if err := groups.Associate(&Group{ID: 1}, "Members", &alice); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := members.Filter(orm.WithParameter("groups__name", "admin")).All()
+	if got := names(t, rows, err); got != "[alice]" {
+		t.Errorf("members of admin are wrong: %v", got)
+	}
+	rows, err = members.Related(&bob, "Groups").Filter(orm.WithParameter("members__name", "alice")).All()
+	if got := names(t, rows, err); got != "[dev]" {
+		t.Errorf("groups of both bob and alice are wrong: %v", got)
+	}
+}
+
+// Shop is a test table which has many soft deleted products
+type Shop struct {
+	ID       int    `name:"id" primaryKey:"true"`
+	Name     string `name:"name" length:"20"`
+	Products []Product
+}
+
+// Product is a test table with soft delete which belongs to a shop
+type Product struct {
+	ID        int       `name:"id" primaryKey:"true"`
+	ShopID    int       `name:"shop_id" fk:"Shop.id"`
+	Name      string    `name:"name" length:"20"`
+	DeletedAt time.Time `name:"deleted_at" softDelete:"true"`
+	Shop      *Shop
+}
+
+func TestJoinSoftDelete(t *testing.T) {
+	defer deleteTestDatabase()
+	db := createTestDatabase()
+	shops, err := tables.NewStructTagsTable(db, &Shop{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	products, err := tables.NewStructTagsTable(db, &Product{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shops.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := products.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := shops.AddMany([]Shop{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := products.AddMany([]Product{{ID: 1, ShopID: 1, Name: "pen"}, {ID: 2, ShopID: 2, Name: "pen"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := products.Delete(&Product{ID: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the soft deleted product of shop b doesn't match
+	rows, err := shops.Filter(orm.WithParameter("products__name", "pen")).All()
+	if err != nil || fmt.Sprint(rows) != "[{1 a []}]" {
+		t.Errorf("expect shop a, but got %v: %v", rows, err)
+	}
+	rows, err = shops.Filter(orm.WithParameter("products__id__isnull", true)).All()
+	if err != nil || fmt.Sprint(rows) != "[{2 b []}]" {
+		t.Errorf("expect shop b without products, but got %v: %v", rows, err)
+	}
+}
+
+func TestJoinSQL(t *testing.T) {
+	db := createRecordingDatabase()
+	posts, err := tables.NewStructTagsTable(db, &Post{}, tables.WithDialect(dialects.NewPostgres()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		filter orm.FilterSet
+		expect string
+	}{
+		{
+			filter: posts.Filter(orm.WithParameter("author__name", "bob")).OrderBy("-author__name"),
+			expect: `SELECT "Post"."id","Post"."author_id","Post"."title" FROM "Post" ` +
+				`LEFT JOIN "Author" AS "author" ON "author"."id" = "Post"."author_id" ` +
+				`WHERE EXISTS (SELECT 1 FROM "Post" AS "__Post" LEFT JOIN "Author" AS "author" ON "author"."id" = "__Post"."author_id" ` +
+				`WHERE "__Post"."id" = "Post"."id" AND "author"."name" = $1) ORDER BY "author"."name" DESC`,
+		},
+		{
+			filter: posts.Filter(orm.WithParameter("title", "a1")).OrderBy("author__name"),
+			expect: `SELECT "Post"."id","Post"."author_id","Post"."title" FROM "Post" ` +
+				`LEFT JOIN "Author" AS "author" ON "author"."id" = "Post"."author_id" ` +
+				`WHERE "Post"."title" = $1 ORDER BY "author"."name"`,
+		},
+	}
+	for _, c := range cases {
+		if _, err := c.filter.All(); err != nil {
+			t.Fatal(err)
+		}
+		if sql, _ := rec.last(); sql != c.expect {
+			t.Errorf("expect %v\nbut got %v", c.expect, sql)
+		}
+	}
+}
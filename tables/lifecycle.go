@@ -0,0 +1,60 @@
+package tables
+
+import "github.com/zgljl2012/go-orm"
+
+// tableExists check whether the table named name exists
+func (t *simpleTable) tableExists(tx executor, name string) (bool, error) {
+	query, values := t.dialect.TableExistsQuery(name)
+	var count int
+	if err := tx.QueryRowContext(t.ctx, query, values...).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// TableExists check whether the table exists in the database
+func (t *simpleTable) TableExists() (bool, error) {
+	return t.tableExists(t.executor(), t.Name())
+}
+
+// Drop the table
+func (t *simpleTable) Drop(ifExists bool) error {
+	sql := "DROP TABLE "
+	if ifExists {
+		sql += "IF EXISTS "
+	}
+	return t.execDDL(t.executor(), sql+t.quote(t.Name()))
+}
+
+// Truncate delete all rows and reset the auto increment sequence in one transaction
+func (t *simpleTable) Truncate() error {
+	statements, sequences := t.dialect.Truncate(t.Name())
+	return t.transaction(t.ctx, func(tx executor) error {
+		if err := t.execDDL(tx, statements[0]); err != nil {
+			return err
+		}
+		if sequences != "" {
+			exists, err := t.tableExists(tx, sequences)
+			if err != nil || !exists {
+				return err
+			}
+		}
+		for _, sql := range statements[1:] {
+			if err := t.execDDL(tx, sql); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Rename the table to newName, return a copy of the table which operates on newName
+func (t *simpleTable) Rename(newName string) (orm.Table, error) {
+	sql := "ALTER TABLE " + t.quote(t.Name()) + " RENAME TO " + t.quote(newName)
+	if err := t.execDDL(t.executor(), sql); err != nil {
+		return nil, err
+	}
+	table := *t
+	table.name = newName
+	return &table, nil
+}
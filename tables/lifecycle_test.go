@@ -0,0 +1,99 @@
+package tables_test
+
+import (
+	"testing"
+
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+func TestTableLifecycle(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exists, err := table.TableExists(); err != nil || exists {
+		t.Fatalf("table should not exist: %v", err)
+	}
+	if err := table.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := table.TableExists(); err != nil || !exists {
+		t.Fatalf("table should exist: %v", err)
+	}
+
+	// truncate
+	for i := 1; i <= 3; i++ {
+		if err := table.Add(&User{ID: i, Username: "username"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := table.Truncate(); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := table.Filter().Count(); err != nil || count != 0 {
+		t.Errorf("table should be empty, but got %d rows: %v", count, err)
+	}
+
+	// rename
+	old := table
+	table, err = table.Rename("Member")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Name() != "Member" || old.Name() != "User" {
+		t.Errorf("names should be Member and User, but got %s and %s", table.Name(), old.Name())
+	}
+	if err := table.Add(&User{ID: 1, Username: "username"}); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := old.TableExists(); err != nil || exists {
+		t.Errorf("the old table should not exist: %v", err)
+	}
+
+	// drop
+	if err := table.Drop(false); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := table.TableExists(); err != nil || exists {
+		t.Errorf("table should be dropped: %v", err)
+	}
+	if err := table.Drop(true); err != nil {
+		t.Error(err)
+	}
+	if err := table.Drop(false); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+}
+
+func TestTruncateResetsSequence(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE "User"("id" INTEGER PRIMARY KEY AUTOINCREMENT, "username" CHAR(20) NULL,
+		"password" CHAR(50) NULL, "active" BOOL NOT NULL DEFAULT 0, "age" FLOAT NULL, "created_at" DATETIME NULL, "count" BIGINT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := db.Exec(`INSERT INTO "User"("username") VALUES ('username')`); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := table.Truncate(); err != nil {
+		t.Fatal(err)
+	}
+	result, err := db.Exec(`INSERT INTO "User"("username") VALUES ('username')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, err := result.LastInsertId(); err != nil || id != 1 {
+		t.Errorf("the sequence should be reset, but got id %d: %v", id, err)
+	}
+}
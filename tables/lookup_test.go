@@ -0,0 +1,177 @@
+package tables_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/dialects"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+func TestLookups(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Create(true); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= 10; i++ {
+		user := User{
+			ID:        i,
+			Username:  fmt.Sprintf("user%d", i),
+			Password:  "pwd",
+			Age:       float32(10 * i),
+			CreatedAt: start.AddDate(0, 0, i),
+		}
+		if i == 10 {
+			user.Username = "Bob_100%"
+		}
+		if err := table.Add(&user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		parameters []orm.Condition
+		expect     []int
+	}{
+		{[]orm.Condition{orm.WithParameter("ID__exact", 2)}, []int{2}},
+		{[]orm.Condition{orm.WithParameter("ID__ne", 2), orm.WithParameter("ID__lt", 4)}, []int{1, 3}},
+		{[]orm.Condition{orm.WithParameter("age__gte", 80)}, []int{8, 9, 10}},
+		{[]orm.Condition{orm.WithParameter("age__gt", 80)}, []int{9, 10}},
+		{[]orm.Condition{orm.WithParameter("age__lte", 20)}, []int{1, 2}},
+		{[]orm.Condition{orm.WithParameter("username__contains", "er1")}, []int{1}},
+		{[]orm.Condition{orm.WithParameter("username__contains", "_100%")}, []int{10}},
+		{[]orm.Condition{orm.WithParameter("username__icontains", "BOB")}, []int{10}},
+		{[]orm.Condition{orm.WithParameter("username__startswith", "user")}, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{[]orm.Condition{orm.WithParameter("username__endswith", "0%")}, []int{10}},
+		{[]orm.Condition{orm.WithParameter("ID__in", []int{2, 4, 6})}, []int{2, 4, 6}},
+		{[]orm.Condition{orm.WithParameter("ID__in", []int{})}, []int{}},
+		{[]orm.Condition{orm.WithParameter("created_at__range", []time.Time{start.AddDate(0, 0, 3), start.AddDate(0, 0, 5)})}, []int{3, 4, 5}},
+		{[]orm.Condition{orm.WithParameter("created_at__isnull", false), orm.WithParameter("ID__lte", 2)}, []int{1, 2}},
+		{[]orm.Condition{orm.WithParameter("created_at__isnull", true)}, []int{}},
+	}
+
+	for _, c := range cases {
+		rows, err := table.Filter(c.parameters...).OrderBy("ID").All()
+		if err != nil {
+			t.Errorf("%v: %v", c.parameters[0].(*orm.QueryParameter).Name, err)
+			continue
+		}
+		ids := []int{}
+		for _, row := range rows {
+			ids = append(ids, row.(User).ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(c.expect) {
+			parameter := c.parameters[0].(*orm.QueryParameter)
+			t.Errorf("%v %v: expect %v, but got %v", parameter.Name, parameter.Operator, c.expect, ids)
+		}
+	}
+
+	// invalid values
+	for _, parameter := range []*orm.QueryParameter{
+		orm.WithParameter("ID__in", 1),
+		orm.WithParameter("ID__range", []int{1}),
+		orm.WithParameter("created_at__isnull", "yes"),
+		{Name: "ID", Value: 1, Operator: "like"},
+	} {
+		if _, err := table.Filter(parameter).All(); err == nil {
+			t.Errorf("%v %v: should got an error, but is normal", parameter.Name, parameter.Operator)
+		}
+	}
+}
+
+func TestLookupsSQL(t *testing.T) {
+	db := createRecordingDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{}, tables.WithDialect(dialects.NewPostgres()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := table.Filter(
+		orm.WithParameter("ID__in", []int{1, 2, 3}),
+		orm.WithParameter("username__icontains", "bob"),
+		orm.WithParameter("age__range", []float32{18, 30}),
+	).All(); err != nil {
+		t.Fatal(err)
+	}
+	expect := `SELECT "id","username","password","active","age","created_at","count" FROM "User" WHERE "id" IN ($1,$2,$3) AND LOWER("username") LIKE LOWER($4) ESCAPE '!' AND "age" BETWEEN $5 AND $6`
+	if sql, args := rec.last(); sql != expect {
+		t.Errorf("expect %v\nbut got %v", expect, sql)
+	} else if len(args) != 6 || args[3] != "%bob%" {
+		t.Errorf("arguments are wrong: %v", args)
+	}
+}
+
+func TestConditionGroups(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Create(true); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 6; i++ {
+		user := User{ID: i, Username: fmt.Sprintf("user%d", i), Active: i%2 == 0}
+		if err := table.Add(&user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		filter orm.FilterSet
+		expect []int
+	}{
+		// (id = 1 OR id = 2) AND NOT active
+		{table.Filter(orm.Or(orm.WithParameter("ID", 1), orm.WithParameter("ID", 2)), orm.Not(orm.WithParameter("active", true))), []int{1}},
+		{table.Filter(orm.Or(orm.WithParameter("ID__lt", 2), orm.And(orm.WithParameter("ID__gt", 3), orm.WithParameter("active", true)))), []int{1, 4, 6}},
+		{table.Filter(orm.WithParameter("ID__lte", 4)).Exclude(orm.WithParameter("active", true)), []int{1, 3}},
+		{table.Filter().Exclude(orm.WithParameter("active", true), orm.WithParameter("ID__gt", 2)), []int{1, 2, 3, 5}},
+		{table.Filter(orm.Or(), orm.And()), []int{1, 2, 3, 4, 5, 6}},
+	}
+	for i, c := range cases {
+		rows, err := c.filter.OrderBy("ID").All()
+		if err != nil {
+			t.Errorf("case %v: %v", i, err)
+			continue
+		}
+		ids := []int{}
+		for _, row := range rows {
+			ids = append(ids, row.(User).ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(c.expect) {
+			t.Errorf("case %v: expect %v, but got %v", i, c.expect, ids)
+		}
+	}
+
+	// check SQL
+	db = createRecordingDatabase()
+	table, err = tables.NewStructTagsTable(db, &User{}, tables.WithDialect(dialects.NewPostgres()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.Filter(
+		orm.Or(orm.WithParameter("username", "a"), orm.WithParameter("username", "b")),
+	).Exclude(orm.WithParameter("active", true)).All(); err != nil {
+		t.Fatal(err)
+	}
+	expect := `SELECT "id","username","password","active","age","created_at","count" FROM "User" WHERE ("username" = $1 OR "username" = $2) AND NOT ("active" = $3)`
+	if sql, _ := rec.last(); sql != expect {
+		t.Errorf("expect %v\nbut got %v", expect, sql)
+	}
+}
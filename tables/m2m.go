@@ -0,0 +1,255 @@
+package tables
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/zgljl2012/go-orm"
+	log "github.com/zgljl2012/slog"
+)
+
+// manyToMany a many-to-many relation declared by a field of slice with m2m tag, e.g.
+//
+//	Groups []Group `m2m:"user_groups"`
+//
+// the join table has two columns named <table>_<primary key> referencing both tables, e.g. user_id and group_id
+type manyToMany struct {
+	target       *simpleTable
+	join         string    // name of join table
+	ownerKey     orm.Field // primary key of the owner
+	targetKey    orm.Field // primary key of the related table
+	ownerColumn  string    // column of join table references the owner
+	targetColumn string    // column of join table references the related table
+}
+
+// primaryKey return the only primary key of table
+func (t *simpleTable) primaryKey() (orm.Field, error) {
+	var key orm.Field
+	for _, field := range t.fields {
+		if field.PrimaryKey() {
+			if key != nil {
+				return nil, fmt.Errorf("%s: %s", ErrCompositePrimaryKey, t.Name())
+			}
+			key = field
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%s: %s", ErrCompositePrimaryKey, t.Name())
+	}
+	return key, nil
+}
+
+// manyToMany resolve the many-to-many relation field named name
+func (t *simpleTable) manyToMany(name string) (*manyToMany, error) {
+	field, ok := reflect.TypeOf(t.table).Elem().FieldByName(name)
+	if !ok || field.Tag.Get("m2m") == "" || field.Type.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%s: %s", ErrRelationNotExists, name)
+	}
+	typ := field.Type.Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: %s", ErrRelationNotExists, name)
+	}
+	instance := reflect.New(typ).Interface()
+	targetFields, err := modelFields(instance)
+	if err != nil {
+		return nil, err
+	}
+	m := &manyToMany{join: field.Tag.Get("m2m")}
+	m.target = newSimpleTable(t.db, instance, targetFields, WithDialect(t.dialect))
+	m.target.ctx, m.target.tx = t.ctx, t.tx
+	if m.ownerKey, err = t.primaryKey(); err != nil {
+		return nil, err
+	}
+	if m.targetKey, err = m.target.primaryKey(); err != nil {
+		return nil, err
+	}
+	m.ownerColumn = strings.ToLower(t.Name()) + "_" + m.ownerKey.Name()
+	m.targetColumn = strings.ToLower(m.target.Name()) + "_" + m.targetKey.Name()
+	if m.targetColumn == m.ownerColumn {
+		// self-referential
+		m.targetColumn = "related_" + m.targetColumn
+	}
+	return m, nil
+}
+
+// manyToManys resolve all many-to-many relations of table
+func (t *simpleTable) manyToManys() ([]*manyToMany, error) {
+	relations := []*manyToMany{}
+	typ := reflect.TypeOf(t.table).Elem()
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).Tag.Get("m2m") == "" {
+			continue
+		}
+		m, err := t.manyToMany(typ.Field(i).Name)
+		if err != nil {
+			return nil, err
+		}
+		relations = append(relations, m)
+	}
+	return relations, nil
+}
+
+// createSQL build the CREATE TABLE statement of join table, the rows are deleted along with either side
+func (m *manyToMany) createSQL(owner *simpleTable) string {
+	q := owner.quote
+	column := func(name string, key orm.Field) string {
+		return q(name) + " " + owner.dialect.DataType(key) + " NOT NULL"
+	}
+	foreignKey := func(name, table string, key orm.Field) string {
+		return "FOREIGN KEY(" + q(name) + ") REFERENCES " + q(table) + "(" + q(key.Name()) + ") ON DELETE CASCADE"
+	}
+	return "CREATE TABLE IF NOT EXISTS " + q(m.join) + "(" +
+		column(m.ownerColumn, m.ownerKey) + "," +
+		column(m.targetColumn, m.targetKey) +
+		", PRIMARY KEY(" + q(m.ownerColumn) + "," + q(m.targetColumn) + "), " +
+		foreignKey(m.ownerColumn, owner.Name(), m.ownerKey) + ", " +
+		foreignKey(m.targetColumn, m.target.Name(), m.targetKey) + ")"
+}
+
+// createJoinTables create the join tables of many-to-many relations if they don't exist
+func (t *simpleTable) createJoinTables(tx executor) error {
+	relations, err := t.manyToManys()
+	if err != nil {
+		return err
+	}
+	for _, m := range relations {
+		if err := t.execDDL(tx, m.createSQL(t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// key return the value of field of instance, instance should be the struct of table or a pointer of it
+func (t *simpleTable) key(instance interface{}, field orm.Field) (interface{}, error) {
+	value := reflect.Indirect(reflect.ValueOf(instance))
+	if value.Type() != reflect.TypeOf(t.table).Elem() {
+		return nil, fmt.Errorf("%s: %s", ErrInstanceType, t.Name())
+	}
+	return value.FieldByName(field.ID()).Interface(), nil
+}
+
+// keys return the primary keys of related instances
+func (m *manyToMany) keys(related []interface{}) ([]interface{}, error) {
+	keys := make([]interface{}, len(related))
+	for i, instance := range related {
+		key, err := m.target.key(instance, m.targetKey)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// Associate add the related instances to the many-to-many relation of owner in one transaction,
+// the existing associations are ignored
+func (t *simpleTable) Associate(owner interface{}, relation string, related ...interface{}) error {
+	m, err := t.manyToMany(relation)
+	if err != nil {
+		return err
+	}
+	ownerKey, err := t.key(owner, m.ownerKey)
+	if err != nil {
+		return err
+	}
+	keys, err := m.keys(related)
+	if err != nil {
+		return err
+	}
+	clause := t.dialect.OnConflict([]string{m.ownerColumn, m.targetColumn}, nil)
+	// two parameters per row
+	size := t.dialect.MaxParameters() / 2
+	return t.transaction(t.ctx, func(tx executor) error {
+		for start := 0; start < len(keys); start += size {
+			end := start + size
+			if end > len(keys) {
+				end = len(keys)
+			}
+			p := newParams(t.dialect)
+			rows := []string{}
+			for _, key := range keys[start:end] {
+				rows = append(rows, "("+p.add(ownerKey)+","+p.add(key)+")")
+			}
+			sql := "INSERT INTO " + t.quote(m.join) + " (" + t.quote(m.ownerColumn) + "," + t.quote(m.targetColumn) +
+				") VALUES " + strings.Join(rows, ",")
+			if clause != "" {
+				sql += " " + clause
+			}
+			log.Debug(sql)
+			if _, err := tx.ExecContext(t.ctx, sql, p.values...); err != nil {
+				log.Error("got an error when associate", "relation", relation, "err", err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Dissociate remove the related instances from the many-to-many relation of owner in one transaction,
+// all associations of owner are removed if related is empty
+func (t *simpleTable) Dissociate(owner interface{}, relation string, related ...interface{}) error {
+	m, err := t.manyToMany(relation)
+	if err != nil {
+		return err
+	}
+	ownerKey, err := t.key(owner, m.ownerKey)
+	if err != nil {
+		return err
+	}
+	keys, err := m.keys(related)
+	if err != nil {
+		return err
+	}
+	// one parameter for owner
+	size := t.dialect.MaxParameters() - 1
+	return t.transaction(t.ctx, func(tx executor) error {
+		for start := 0; start == 0 || start < len(keys); start += size {
+			end := start + size
+			if end > len(keys) {
+				end = len(keys)
+			}
+			p := newParams(t.dialect)
+			sql := "DELETE FROM " + t.quote(m.join) + " WHERE " + t.quote(m.ownerColumn) + " = " + p.add(ownerKey)
+			if len(keys) > 0 {
+				placeholders := []string{}
+				for _, key := range keys[start:end] {
+					placeholders = append(placeholders, p.add(key))
+				}
+				sql += " AND " + t.quote(m.targetColumn) + " IN (" + strings.Join(placeholders, ",") + ")"
+			}
+			log.Debug(sql)
+			if _, err := tx.ExecContext(t.ctx, sql, p.values...); err != nil {
+				log.Error("got an error when dissociate", "relation", relation, "err", err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Related return the filter set of the rows related to owner by the many-to-many relation
+func (t *simpleTable) Related(owner interface{}, relation string) orm.FilterSet {
+	m, err := t.manyToMany(relation)
+	if err != nil {
+		return &filterSet{ctx: t.ctx, table: t, err: err}
+	}
+	ownerKey, err := t.key(owner, m.ownerKey)
+	if err != nil {
+		return &filterSet{ctx: t.ctx, table: t, err: err}
+	}
+	f := newFilterSet(m.target).(*filterSet)
+	f.scopes = append(f.scopes, func(p *params, resolve resolver) (string, error) {
+		column, err := resolve(m.targetKey.Name())
+		if err != nil {
+			return "", err
+		}
+		return column + " IN (SELECT " + t.quote(m.targetColumn) + " FROM " + t.quote(m.join) +
+			" WHERE " + t.quote(m.ownerColumn) + " = " + p.add(ownerKey) + ")", nil
+	})
+	return f
+}
@@ -0,0 +1,146 @@
+package tables_test
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+// Member is a test table which joins many groups
+type Member struct {
+	ID     int     `name:"id" primaryKey:"true"`
+	Name   string  `name:"name" length:"20"`
+	Groups []Group `m2m:"member_groups"`
+}
+
+// Group is a test table which has many members
+type Group struct {
+	ID      int      `name:"id" primaryKey:"true"`
+	Name    string   `name:"name" length:"20"`
+	Members []Member `m2m:"member_groups"`
+}
+
+func names(t *testing.T, rows []interface{}, err error) string {
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := []string{}
+	for _, row := range rows {
+		switch v := row.(type) {
+		case Group:
+			result = append(result, v.Name)
+		case Member:
+			result = append(result, v.Name)
+		}
+	}
+	return fmt.Sprint(result)
+}
+
+func TestManyToMany(t *testing.T) {
+	defer deleteTestDatabase()
+	db, err := sql.Open("sqlite3", testDB+"?_foreign_keys=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	members, err := tables.NewStructTagsTable(db, &Member{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups, err := tables.NewStructTagsTable(db, &Group{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := members.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := groups.Create(false); err != nil {
+		t.Fatal(err)
+	}
+
+	var ddl string
+	if err := db.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'member_groups'`).Scan(&ddl); err != nil {
+		t.Fatal(err)
+	}
+	expect := `CREATE TABLE "member_groups"("member_id" INT NOT NULL,"group_id" INT NOT NULL, PRIMARY KEY("member_id","group_id"), ` +
+		`FOREIGN KEY("member_id") REFERENCES "Member"("id") ON DELETE CASCADE, FOREIGN KEY("group_id") REFERENCES "Group"("id") ON DELETE CASCADE)`
+	if ddl != expect {
+		t.Errorf("expect %v\nbut got %v", expect, ddl)
+	}
+
+	alice, bob := Member{ID: 1, Name: "alice"}, Member{ID: 2, Name: "bob"}
+	admin, dev, ops := Group{ID: 1, Name: "admin"}, Group{ID: 2, Name: "dev"}, Group{ID: 3, Name: "ops"}
+	if err := members.AddMany([]Member{alice, bob}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := groups.AddMany([]Group{admin, dev, ops}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := members.Associate(&alice, "Groups", &admin, dev); err != nil {
+		t.Fatal(err)
+	}
+	// existing associations are ignored
+	if err := members.Associate(alice, "Groups", dev, ops); err != nil {
+		t.Fatal(err)
+	}
+	if err := groups.Associate(&dev, "Members", &bob); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := members.Related(&alice, "Groups").OrderBy("id").All()
+	if got := names(t, rows, err); got != "[admin dev ops]" {
+		t.Errorf("groups of alice are wrong: %v", got)
+	}
+	rows, err = members.Related(&alice, "Groups").Filter(orm.WithParameter("name__startswith", "d")).All()
+	if got := names(t, rows, err); got != "[dev]" {
+		t.Errorf("filtered groups of alice are wrong: %v", got)
+	}
+	rows, err = groups.Related(&dev, "Members").OrderBy("-id").All()
+	if got := names(t, rows, err); got != "[bob alice]" {
+		t.Errorf("members of dev are wrong: %v", got)
+	}
+	if count, err := members.Related(&bob, "Groups").Count(); err != nil || count != 1 {
+		t.Errorf("bob should be in 1 group, but got %d: %v", count, err)
+	}
+
+	// dissociate
+	if err := members.Dissociate(&alice, "Groups", &admin); err != nil {
+		t.Fatal(err)
+	}
+	rows, err = members.Related(&alice, "Groups").OrderBy("id").All()
+	if got := names(t, rows, err); got != "[dev ops]" {
+		t.Errorf("groups of alice are wrong: %v", got)
+	}
+	if err := members.Dissociate(&alice, "Groups"); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := members.Related(&alice, "Groups").Count(); err != nil || count != 0 {
+		t.Errorf("alice should be in no groups, but got %d: %v", count, err)
+	}
+
+	// associations are deleted along with the rows
+	if err := groups.Delete(&dev); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := members.Related(&bob, "Groups").Count(); err != nil || count != 0 {
+		t.Errorf("bob should be in no groups, but got %d: %v", count, err)
+	}
+
+	// wrong relation or instance
+	if err := members.Associate(&alice, "Name", &admin); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if err := members.Associate(&alice, "Groups", &bob); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if _, err := members.Related(&admin, "Groups").All(); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if _, err := members.Filter().Preload("Groups").All(); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+}
@@ -0,0 +1,186 @@
+package tables
+
+import (
+	"strings"
+	"time"
+
+	"github.com/zgljl2012/go-orm"
+	log "github.com/zgljl2012/slog"
+)
+
+// liveColumn a column of the table in database
+type liveColumn struct {
+	name       string
+	columnType string
+	null       bool
+}
+
+// liveColumns list the columns of the table in database, empty if the table doesn't exist
+func (t *simpleTable) liveColumns(tx executor) ([]liveColumn, error) {
+	query, values := t.dialect.ColumnsQuery(t.Name())
+	rows, err := tx.QueryContext(t.ctx, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := []liveColumn{}
+	for rows.Next() {
+		column := liveColumn{}
+		if err := rows.Scan(&column.name, &column.columnType, &column.null); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// Migrate compare the fields with the columns of the existing table
+func (t *simpleTable) Migrate(rebuild bool) (*orm.MigrationReport, error) {
+	report := &orm.MigrationReport{}
+	err := t.transaction(t.ctx, func(tx executor) error {
+		columns, err := t.liveColumns(tx)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			report.Created = true
+			if err := t.execDDL(tx, t.createSQL(t.Name(), false)); err != nil {
+				return err
+			}
+			if report.Indexes, err = t.createIndexes(tx); err != nil {
+				return err
+			}
+			return t.createJoinTables(tx)
+		}
+
+		live := map[string]liveColumn{}
+		for _, column := range columns {
+			live[column.name] = column
+		}
+		missing := []orm.Field{}
+		// NOT NULL columns without default can't be added to the existing rows
+		pending := []orm.Field{}
+		// columns exist in both the table and the fields
+		common := []string{}
+		for _, field := range t.fields {
+			column, ok := live[field.Name()]
+			if !ok {
+				if !field.Null() && field.Default() == nil {
+					pending = append(pending, field)
+				} else {
+					missing = append(missing, field)
+				}
+				continue
+			}
+			delete(live, field.Name())
+			common = append(common, t.quote(field.Name()))
+			expectedType := t.dialect.DataType(field)
+			columnType := t.dialect.NormalizeType(column.columnType)
+			// primary keys are NOT NULL, even if they are only declared by PRIMARY KEY(...)
+			null := column.null && !field.PrimaryKey()
+			if !t.dialect.SameType(columnType, expectedType) || null != field.Null() {
+				report.Drifts = append(report.Drifts, orm.ColumnDrift{
+					Column:       field.Name(),
+					Type:         columnType,
+					ExpectedType: expectedType,
+					Null:         null,
+					ExpectedNull: field.Null(),
+				})
+			}
+		}
+		for _, column := range columns {
+			if _, ok := live[column.name]; ok {
+				report.Unknown = append(report.Unknown, column.name)
+			}
+		}
+
+		// rebuild creates the missing columns too, pending columns are filled with zero values
+		if rebuild && (len(report.Drifts) > 0 || len(pending) > 0) {
+			report.Rebuilt = true
+			for _, field := range append(missing, pending...) {
+				report.Added = append(report.Added, field.Name())
+			}
+			if err := t.rebuild(tx, common, pending); err != nil {
+				return err
+			}
+		} else {
+			for _, field := range pending {
+				report.Pending = append(report.Pending, field.Name())
+			}
+			for _, field := range missing {
+				sql := "ALTER TABLE " + t.quote(t.Name()) + " ADD COLUMN " + t.columnDefinition(field)
+				if err := t.execDDL(tx, sql); err != nil {
+					return err
+				}
+				report.Added = append(report.Added, field.Name())
+			}
+		}
+		// the indexes of rebuilt table are dropped along with the old table
+		if report.Indexes, err = t.createIndexes(tx); err != nil {
+			return err
+		}
+		return t.createJoinTables(tx)
+	})
+	if err != nil {
+		log.Error("got an error when migrate table", "table", t.Name(), "err", err)
+		return nil, err
+	}
+	return report, nil
+}
+
+// rebuild create a new table with the fields, copy the common columns and fill the zero columns
+// with zero values, then replace the old table with it. Unknown columns and the indexes are dropped.
+func (t *simpleTable) rebuild(tx executor, common []string, zeros []orm.Field) error {
+	name := t.Name() + "__migrate"
+	columns := strings.Join(common, ",")
+	values := columns
+	for _, field := range zeros {
+		if columns != "" {
+			columns += ","
+			values += ","
+		}
+		columns += t.quote(field.Name())
+		values += zero(field)
+	}
+	statements := []string{t.createSQL(name, false)}
+	if columns != "" {
+		statements = append(statements,
+			"INSERT INTO "+t.quote(name)+" ("+columns+") SELECT "+values+" FROM "+t.quote(t.Name()))
+	}
+	statements = append(statements,
+		"DROP TABLE "+t.quote(t.Name()),
+		"ALTER TABLE "+t.quote(name)+" RENAME TO "+t.quote(t.Name()),
+	)
+	// the ids are copied, the sequence of the new table should continue after them
+	if field := t.autoIncrement(); field != nil {
+		if sql := t.dialect.SyncSequence(t.Name(), field.Name()); sql != "" {
+			statements = append(statements, sql)
+		}
+	}
+	for _, sql := range statements {
+		if err := t.execDDL(tx, sql); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execDDL execute the statement without parameters
+func (t *simpleTable) execDDL(tx executor, sql string) error {
+	log.Info(sql)
+	_, err := tx.ExecContext(t.ctx, sql)
+	return err
+}
+
+// zero return the literal of the zero value of field
+func zero(field orm.Field) string {
+	switch field.Type() {
+	case "CHAR":
+		return literal("")
+	case "BOOL":
+		return literal(false)
+	case "DATETIME":
+		return literal(time.Time{})
+	}
+	return "0"
+}
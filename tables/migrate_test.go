@@ -0,0 +1,139 @@
+package tables_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+func TestMigrate(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// create if not exists
+	report, err := table.Migrate(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Created {
+		t.Error("table should be created")
+	}
+	if _, err := db.Exec(`DROP TABLE "User"`); err != nil {
+		t.Fatal(err)
+	}
+
+	// an old version of the table, types of the same affinity and the nullable primary key don't drift
+	if _, err := db.Exec(`CREATE TABLE "User"("id" INT, "username" CHAR, "password" INT NULL,
+		"active" BOOL NOT NULL, "extra" TEXT NULL, PRIMARY KEY("id"))`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO "User" VALUES (1, 'username', 'pwd', 1, 'extra')`); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = table.Migrate(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created || report.Rebuilt {
+		t.Errorf("table should not be created or rebuilt: %+v", report)
+	}
+	if fmt.Sprint(report.Added) != "[age created_at count]" {
+		t.Errorf("added columns are wrong: %v", report.Added)
+	}
+	if fmt.Sprint(report.Unknown) != "[extra]" {
+		t.Errorf("unknown columns are wrong: %v", report.Unknown)
+	}
+	expect := []orm.ColumnDrift{{Column: "password", Type: "INT", ExpectedType: "CHAR(50)", Null: true, ExpectedNull: true}}
+	if fmt.Sprint(report.Drifts) != fmt.Sprint(expect) {
+		t.Errorf("expect drifts %v, but got %v", expect, report.Drifts)
+	}
+
+	// the added columns are NULL
+	user := User{}
+	if err := table.Filter().Get(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 1 || user.Username != "username" || !user.Active || user.Age != 0 || !user.CreatedAt.IsZero() {
+		t.Errorf("user is wrong: %+v", user)
+	}
+
+	// rebuild
+	report, err = table.Migrate(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Rebuilt || len(report.Drifts) != 1 {
+		t.Errorf("table should be rebuilt: %+v", report)
+	}
+	if err := table.Filter().Get(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 1 || user.Password != "pwd" {
+		t.Errorf("data should be copied: %+v", user)
+	}
+
+	// up to date
+	report, err = table.Migrate(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created || report.Rebuilt || len(report.Added) != 0 || len(report.Drifts) != 0 || len(report.Unknown) != 0 {
+		t.Errorf("table should be up to date: %+v", report)
+	}
+}
+
+// Profile is a test table with a NOT NULL column
+type Profile struct {
+	ID    int    `name:"id" primaryKey:"true"`
+	Name  string `name:"name" length:"20"`
+	Score int    `name:"score" null:"false"`
+}
+
+func TestMigratePending(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &Profile{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE "Profile"("id" INT NOT NULL, "name" CHAR(20) NULL, PRIMARY KEY("id"))`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO "Profile" VALUES (1, 'name')`); err != nil {
+		t.Fatal(err)
+	}
+
+	// the NOT NULL column can't be added
+	report, err := table.Migrate(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rebuilt || len(report.Added) != 0 || fmt.Sprint(report.Pending) != "[score]" {
+		t.Errorf("score should be pending: %+v", report)
+	}
+
+	// rebuild fills it with zero value
+	report, err = table.Migrate(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Rebuilt || fmt.Sprint(report.Added) != "[score]" || len(report.Pending) != 0 {
+		t.Errorf("table should be rebuilt: %+v", report)
+	}
+	profile := Profile{}
+	if err := table.Filter().Get(&profile); err != nil {
+		t.Fatal(err)
+	}
+	if profile.ID != 1 || profile.Name != "name" || profile.Score != 0 {
+		t.Errorf("profile is wrong: %+v", profile)
+	}
+}
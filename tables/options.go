@@ -0,0 +1,50 @@
+package tables
+
+import (
+	"time"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/dialects"
+)
+
+// Function Options Pattern
+
+// TableOptions options of table
+type TableOptions struct {
+	Dialect orm.Dialect
+	Name    string
+	// Clock return the current time which stamps the auto time fields
+	Clock func() time.Time
+}
+
+func defaultOptions() TableOptions {
+	return TableOptions{
+		Dialect: dialects.NewSQLite(),
+		Clock:   time.Now,
+	}
+}
+
+// TableOption option setter
+type TableOption func(options *TableOptions)
+
+// WithDialect set the dialect of database, default is SQLite
+func WithDialect(dialect orm.Dialect) TableOption {
+	return func(options *TableOptions) {
+		options.Dialect = dialect
+	}
+}
+
+// WithName set the name of table, default is the name of the struct
+func WithName(name string) TableOption {
+	return func(options *TableOptions) {
+		options.Name = name
+	}
+}
+
+// WithClock set the function which returns the current time, default is time.Now.
+// The auto time fields are stamped with it, so that it can be fixed in tests.
+func WithClock(clock func() time.Time) TableOption {
+	return func(options *TableOptions) {
+		options.Clock = clock
+	}
+}
@@ -0,0 +1,160 @@
+package tables
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/zgljl2012/go-orm"
+)
+
+// params collects the values bound to a statement and renders their placeholders
+type params struct {
+	dialect orm.Dialect
+	values  []interface{}
+}
+
+func newParams(dialect orm.Dialect) *params {
+	return &params{
+		dialect: dialect,
+		values:  []interface{}{},
+	}
+}
+
+// add bind a value, return the placeholder of it
+func (p *params) add(value interface{}) string {
+	p.values = append(p.values, value)
+	return p.dialect.Placeholder(len(p.values))
+}
+
+// resolver resolve the name in query parameter to a SQL expression, e.g. a quoted column
+type resolver func(name string) (string, error)
+
+// leaf render a query parameter of the condition tree to SQL
+type leaf func(parameter *orm.QueryParameter) (string, error)
+
+// where render the condition tree to SQL, its values are bound to p
+func (t *simpleTable) where(condition orm.Condition, resolve resolver, p *params) (string, error) {
+	return t.render(condition, func(parameter *orm.QueryParameter) (string, error) {
+		column, err := resolve(parameter.Name)
+		if err != nil {
+			return "", err
+		}
+		return lookup(parameter, column, p)
+	})
+}
+
+// render the condition tree to SQL, query parameters are rendered by render
+func (t *simpleTable) render(condition orm.Condition, render leaf) (string, error) {
+	switch c := condition.(type) {
+	case *orm.QueryParameter:
+		return render(c)
+	case *orm.Group:
+		children := []string{}
+		for _, child := range c.Conditions {
+			sql, err := t.render(child, render)
+			if err != nil {
+				return "", err
+			}
+			if sql != "" {
+				children = append(children, sql)
+			}
+		}
+		if len(children) == 0 {
+			return "", nil
+		}
+		if c.Connector != orm.ConnectorAnd && c.Connector != orm.ConnectorOr {
+			return "", fmt.Errorf(`%s: "%s"`, ErrUnsupportedConnector, c.Connector)
+		}
+		sql := strings.Join(children, " "+c.Connector+" ")
+		if c.Negated {
+			return "NOT (" + sql + ")", nil
+		}
+		if len(children) > 1 {
+			sql = "(" + sql + ")"
+		}
+		return sql, nil
+	}
+	return "", fmt.Errorf("unsupported condition: %T", condition)
+}
+
+// likeEscape is the escape character of LIKE patterns, backslash is not portable across databases
+const likeEscape = "!"
+
+// escapeLike escape the wildcards in value
+func escapeLike(value interface{}) string {
+	return strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_").
+		Replace(fmt.Sprint(value))
+}
+
+// lookup render a query parameter on column to SQL, its values are bound to p
+func lookup(parameter *orm.QueryParameter, column string, p *params) (string, error) {
+	like := " LIKE %s ESCAPE '" + likeEscape + "'"
+	switch parameter.Operator {
+	case orm.OpEqual, orm.OpNotEqual:
+		// compare with NULL
+		if parameter.Value == nil {
+			if parameter.Operator == orm.OpEqual {
+				return column + " IS NULL", nil
+			}
+			return column + " IS NOT NULL", nil
+		}
+		return column + " " + parameter.Operator + " " + p.add(parameter.Value), nil
+	case orm.OpGreaterThan, orm.OpGreaterOrEqual, orm.OpLessThan, orm.OpLessOrEqual:
+		return column + " " + parameter.Operator + " " + p.add(parameter.Value), nil
+	case orm.OpContains:
+		return column + fmt.Sprintf(like, p.add("%"+escapeLike(parameter.Value)+"%")), nil
+	case orm.OpIContains:
+		return "LOWER(" + column + ")" + fmt.Sprintf(like, "LOWER("+p.add("%"+escapeLike(parameter.Value)+"%")+")"), nil
+	case orm.OpStartsWith:
+		return column + fmt.Sprintf(like, p.add(escapeLike(parameter.Value)+"%")), nil
+	case orm.OpEndsWith:
+		return column + fmt.Sprintf(like, p.add("%"+escapeLike(parameter.Value))), nil
+	case orm.OpIn:
+		values, err := slice(parameter)
+		if err != nil {
+			return "", err
+		}
+		// nothing matches an empty list
+		if len(values) == 0 {
+			return "1 = 0", nil
+		}
+		placeholders := []string{}
+		for _, value := range values {
+			placeholders = append(placeholders, p.add(value))
+		}
+		return column + " IN (" + strings.Join(placeholders, ",") + ")", nil
+	case orm.OpRange:
+		values, err := slice(parameter)
+		if err != nil {
+			return "", err
+		}
+		if len(values) != 2 {
+			return "", fmt.Errorf(`%s: "%s" needs 2 values, but got %d`, ErrInvalidValue, parameter.Name, len(values))
+		}
+		return column + " BETWEEN " + p.add(values[0]) + " AND " + p.add(values[1]), nil
+	case orm.OpIsNull:
+		isNull, ok := parameter.Value.(bool)
+		if !ok {
+			return "", fmt.Errorf(`%s: "%s" needs a bool, but got %v`, ErrInvalidValue, parameter.Name, parameter.Value)
+		}
+		if isNull {
+			return column + " IS NULL", nil
+		}
+		return column + " IS NOT NULL", nil
+	}
+	return "", fmt.Errorf(`%s: "%s"`, ErrUnsupportedOperator, parameter.Operator)
+}
+
+// slice convert the value of parameter which should be a slice or an array to []interface{}
+func slice(parameter *orm.QueryParameter) ([]interface{}, error) {
+	value := reflect.ValueOf(parameter.Value)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf(`%s: "%s" needs a slice, but got %v`, ErrInvalidValue, parameter.Name, parameter.Value)
+	}
+	values := make([]interface{}, value.Len())
+	for i := range values {
+		values[i] = value.Index(i).Interface()
+	}
+	return values, nil
+}
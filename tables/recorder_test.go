@@ -0,0 +1,152 @@
+package tables_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+)
+
+// recorder is a database/sql driver which records statements instead of executing them,
+// so that the SQL generated for databases we don't have can be asserted.
+type recorder struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]driver.Value
+	rows       [][]driver.Value // returned by the next query which isn't COUNT
+}
+
+var rec = &recorder{}
+
+func init() {
+	sql.Register("recorder", rec)
+}
+
+// createRecordingDatabase open a database whose statements are recorded
+func createRecordingDatabase() *sql.DB {
+	rec.reset()
+	db, err := sql.Open("recorder", "")
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+func (r *recorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = nil
+	r.args = nil
+	r.rows = nil
+}
+
+// returns set the rows returned by the next query which isn't COUNT
+func (r *recorder) returns(rows ...[]driver.Value) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rows = rows
+}
+
+func (r *recorder) record(query string, args []driver.Value) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, query)
+	r.args = append(r.args, args)
+}
+
+// last return the last statement and its arguments
+func (r *recorder) last() (string, []driver.Value) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.statements) == 0 {
+		return "", nil
+	}
+	return r.statements[len(r.statements)-1], r.args[len(r.args)-1]
+}
+
+func (r *recorder) Open(name string) (driver.Conn, error) {
+	return &recordConn{r: r}, nil
+}
+
+type recordConn struct {
+	r *recorder
+}
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordStmt{r: c.r, query: query}, nil
+}
+
+func (c *recordConn) Close() error {
+	return nil
+}
+
+func (c *recordConn) Begin() (driver.Tx, error) {
+	return &recordTx{}, nil
+}
+
+type recordTx struct{}
+
+func (tx *recordTx) Commit() error {
+	return nil
+}
+
+func (tx *recordTx) Rollback() error {
+	return nil
+}
+
+type recordStmt struct {
+	r     *recorder
+	query string
+}
+
+func (s *recordStmt) Close() error {
+	return nil
+}
+
+func (s *recordStmt) NumInput() int {
+	return -1
+}
+
+func (s *recordStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.r.record(s.query, args)
+	return driver.RowsAffected(1), nil
+}
+
+// Query COUNT queries get one row with 1, others get nothing
+func (s *recordStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.r.record(s.query, args)
+	if strings.Contains(s.query, "COUNT(") {
+		return &recordRows{columns: []string{"count"}, values: [][]driver.Value{{int64(1)}}}, nil
+	}
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	rows := &recordRows{values: s.r.rows}
+	if len(s.r.rows) > 0 {
+		rows.columns = make([]string, len(s.r.rows[0]))
+	}
+	s.r.rows = nil
+	return rows, nil
+}
+
+type recordRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *recordRows) Columns() []string {
+	return r.columns
+}
+
+func (r *recordRows) Close() error {
+	return nil
+}
+
+func (r *recordRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
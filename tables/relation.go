@@ -0,0 +1,184 @@
+package tables
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/fields"
+)
+
+// relation a belongs-to relation declared by a field of pointer of struct, e.g. Author *Author,
+// or a has-many relation declared by a field of slice, e.g. Posts []Post.
+// The foreign key is found by fk tags, relation tag specifies its column if there are multiple ones, e.g.
+//
+//	Editor *Author `relation:"editor_id"`
+type relation struct {
+	field  reflect.StructField // relation field of the owner
+	target *simpleTable        // the related table
+	many   bool                // has-many
+	local  orm.Field           // field of the owner
+	remote orm.Field           // field of the related table whose value equals local
+}
+
+// modelFields return the fields of instance, which implements ModelFields or is declared with tags
+func modelFields(instance interface{}) ([]orm.Field, error) {
+	if model, ok := instance.(orm.ModelFields); ok {
+		return model.Fields(), nil
+	}
+	return fields.ParseStructWithTagsToFields(instance)
+}
+
+// relation resolve the relation field named name
+func (t *simpleTable) relation(name string) (*relation, error) {
+	field, ok := reflect.TypeOf(t.table).Elem().FieldByName(name)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s", ErrRelationNotExists, name)
+	}
+	if _, ok := field.Tag.Lookup("m2m"); ok {
+		return nil, fmt.Errorf("%s: %s is many-to-many, query it with Related", ErrRelationNotExists, name)
+	}
+	r := &relation{field: field}
+	typ := field.Type
+	if typ.Kind() == reflect.Slice {
+		r.many = true
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: %s", ErrRelationNotExists, name)
+	}
+	instance := reflect.New(typ).Interface()
+	targetFields, err := modelFields(instance)
+	if err != nil {
+		return nil, err
+	}
+	r.target = newSimpleTable(t.db, instance, targetFields, WithDialect(t.dialect))
+	r.target.tx = t.tx
+
+	column := field.Tag.Get("relation")
+	if r.many {
+		// the foreign key is in the related table
+		if r.remote, err = r.target.foreignKey(t.Name(), column); err != nil {
+			return nil, err
+		}
+		r.local, err = t.field(r.remote.ForeignKey().Column)
+	} else {
+		if r.local, err = t.foreignKey(r.target.Name(), column); err != nil {
+			return nil, err
+		}
+		r.remote, err = r.target.field(r.local.ForeignKey().Column)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// foreignKey find the field which references table, column is its name if it's not empty
+func (t *simpleTable) foreignKey(table, column string) (orm.Field, error) {
+	var found orm.Field
+	for _, field := range t.fields {
+		fk := field.ForeignKey()
+		if fk == nil || fk.Table != table || (column != "" && field.Name() != column) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("%s: %s references %s more than once, specify the relation tag",
+				ErrForeignKeyNotExists, t.Name(), table)
+		}
+		found = field
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%s: %s doesn't reference %s", ErrForeignKeyNotExists, t.Name(), table)
+	}
+	return found, nil
+}
+
+// load query the related rows of objs with IN queries, then set them to the relation field of objs
+func (r *relation) load(ctx context.Context, objs []reflect.Value) error {
+	keys := []interface{}{}
+	seen := map[interface{}]bool{}
+	for _, obj := range objs {
+		key := obj.FieldByName(r.local.ID()).Interface()
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	related := map[interface{}][]reflect.Value{}
+	size := r.target.dialect.MaxParameters()
+	for start := 0; start < len(keys); start += size {
+		end := start + size
+		if end > len(keys) {
+			end = len(keys)
+		}
+		rows, err := r.target.Filter(&orm.QueryParameter{
+			Name:     r.remote.Name(),
+			Value:    keys[start:end],
+			Operator: orm.OpIn,
+		}).WithContext(ctx).OrderBy(r.target.primaryKeys()...).All()
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			value := reflect.ValueOf(row)
+			key := value.FieldByName(r.remote.ID()).Interface()
+			related[key] = append(related[key], value)
+		}
+	}
+
+	for _, obj := range objs {
+		rows := related[obj.FieldByName(r.local.ID()).Interface()]
+		dst := obj.FieldByIndex(r.field.Index)
+		if r.many {
+			values := reflect.MakeSlice(dst.Type(), 0, len(rows))
+			for _, row := range rows {
+				values = reflect.Append(values, convert(row, dst.Type().Elem()))
+			}
+			dst.Set(values)
+		} else if len(rows) > 0 {
+			dst.Set(convert(rows[0], dst.Type()))
+		} else {
+			dst.Set(reflect.Zero(dst.Type()))
+		}
+	}
+	return nil
+}
+
+// convert the row to typ, which is the struct of row or a pointer of it
+func convert(row reflect.Value, typ reflect.Type) reflect.Value {
+	if typ.Kind() == reflect.Ptr {
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(row)
+		return ptr
+	}
+	return row
+}
+
+// Preload fill the relation fields after querying
+func (f *filterSet) Preload(relations ...string) orm.FilterSet {
+	f.preload = append(f.preload, relations...)
+	return f
+}
+
+// preloadRelations load the relations of objs, which are addressable values of the table struct
+func (f *filterSet) preloadRelations(objs []reflect.Value) error {
+	if len(objs) == 0 {
+		return nil
+	}
+	for _, name := range f.preload {
+		r, err := f.table.relation(name)
+		if err != nil {
+			return err
+		}
+		if err := r.load(f.ctx, objs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
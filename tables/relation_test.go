@@ -0,0 +1,150 @@
+package tables_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+// Author is a test table which has many posts
+type Author struct {
+	ID    int    `name:"id" primaryKey:"true"`
+	Name  string `name:"name" length:"20"`
+	Posts []Post
+}
+
+// Post is a test table which belongs to an author
+type Post struct {
+	ID       int    `name:"id" primaryKey:"true"`
+	AuthorID int    `name:"author_id" fk:"Author.id" onDelete:"CASCADE"`
+	Title    string `name:"title" length:"50"`
+	Author   *Author
+}
+
+func createRelationTables(t *testing.T) (*sql.DB, orm.Table, orm.Table) {
+	db, err := sql.Open("sqlite3", testDB+"?_foreign_keys=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authors, err := tables.NewStructTagsTable(db, &Author{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	posts, err := tables.NewStructTagsTable(db, &Post{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := authors.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := posts.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := authors.AddMany([]Author{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}, {ID: 3, Name: "carol"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := posts.AddMany([]Post{
+		{ID: 1, AuthorID: 1, Title: "a1"},
+		{ID: 2, AuthorID: 2, Title: "b1"},
+		{ID: 3, AuthorID: 1, Title: "a2"},
+	}, 0); err != nil {
+		t.Fatal(err)
+	}
+	return db, authors, posts
+}
+
+func TestForeignKey(t *testing.T) {
+	defer deleteTestDatabase()
+	db, authors, posts := createRelationTables(t)
+
+	var sql string
+	if err := db.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'Post'`).Scan(&sql); err != nil {
+		t.Fatal(err)
+	}
+	expect := `CREATE TABLE "Post"("id" INT NOT NULL,"author_id" INT NULL,"title" CHAR(50) NULL, PRIMARY KEY("id"), ` +
+		`FOREIGN KEY("author_id") REFERENCES "Author"("id") ON DELETE CASCADE)`
+	if sql != expect {
+		t.Errorf("expect %v\nbut got %v", expect, sql)
+	}
+
+	// the referenced row must exist
+	if err := posts.Add(&Post{ID: 4, AuthorID: 4}); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	// cascade
+	if err := authors.Delete(&Author{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := posts.Filter().Count(); err != nil || count != 1 {
+		t.Errorf("posts of author 1 should be deleted, %d posts left: %v", count, err)
+	}
+
+	// onDelete needs fk
+	if _, err := tables.NewStructTagsTable(db, &struct {
+		ID int `name:"id" primaryKey:"true" onDelete:"CASCADE"`
+	}{}); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+}
+
+func TestPreload(t *testing.T) {
+	defer deleteTestDatabase()
+	_, authors, posts := createRelationTables(t)
+
+	// has many
+	rows, err := authors.Filter().OrderBy("id").Preload("Posts").All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expects := [][]string{{"a1", "a2"}, {"b1"}, {}}
+	for i, row := range rows {
+		author := row.(Author)
+		if author.Posts == nil || len(author.Posts) != len(expects[i]) {
+			t.Errorf("posts of %s are wrong: %+v", author.Name, author.Posts)
+			continue
+		}
+		for j, post := range author.Posts {
+			if post.Title != expects[i][j] {
+				t.Errorf("posts of %s are wrong: %+v", author.Name, author.Posts)
+			}
+		}
+	}
+
+	// belongs to
+	rows, err = posts.Filter().OrderBy("id").Preload("Author").All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		post := row.(Post)
+		if post.Author == nil || post.Author.ID != post.AuthorID {
+			t.Errorf("author of %s is wrong: %+v", post.Title, post.Author)
+		}
+	}
+
+	// one row
+	author := Author{}
+	if err := authors.Filter(orm.WithParameter("name", "bob")).Preload("Posts").Get(&author); err != nil {
+		t.Fatal(err)
+	}
+	if len(author.Posts) != 1 || author.Posts[0].Title != "b1" {
+		t.Errorf("posts of bob are wrong: %+v", author.Posts)
+	}
+
+	// without preloading
+	if err := authors.Filter(orm.WithParameter("name", "bob")).Get(&author); err != nil {
+		t.Fatal(err)
+	}
+	if author.Posts != nil {
+		t.Errorf("posts should not be loaded: %+v", author.Posts)
+	}
+
+	if _, err := authors.Filter().Preload("Comments").All(); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if _, err := authors.Filter().Preload("Name").All(); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+}
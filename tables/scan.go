@@ -0,0 +1,86 @@
+package tables
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// nullable scans NULL as the zero value of dst, so that nullable columns,
+// e.g. columns added by migration, can be scanned into plain fields.
+type nullable struct {
+	dst reflect.Value
+}
+
+func (n *nullable) Scan(src interface{}) error {
+	if src == nil {
+		n.dst.Set(reflect.Zero(n.dst.Type()))
+		return nil
+	}
+	switch n.dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v := sql.NullInt64{}
+		if err := v.Scan(src); err != nil {
+			return err
+		}
+		n.dst.SetInt(v.Int64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// values above MaxInt64 can't be scanned by sql.NullInt64
+		var (
+			v   uint64
+			err error
+		)
+		switch s := src.(type) {
+		case int64:
+			if s < 0 {
+				err = fmt.Errorf("negative value %d", s)
+			}
+			v = uint64(s)
+		case uint64:
+			v = s
+		case []byte:
+			v, err = strconv.ParseUint(string(s), 10, 64)
+		case string:
+			v, err = strconv.ParseUint(s, 10, 64)
+		default:
+			err = fmt.Errorf("unsupported type %T", src)
+		}
+		if err == nil && n.dst.OverflowUint(v) {
+			err = fmt.Errorf("value %d out of range", v)
+		}
+		if err != nil {
+			return fmt.Errorf("converting %T to %v: %v", src, n.dst.Type(), err)
+		}
+		n.dst.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v := sql.NullFloat64{}
+		if err := v.Scan(src); err != nil {
+			return err
+		}
+		n.dst.SetFloat(v.Float64)
+	case reflect.Bool:
+		v := sql.NullBool{}
+		if err := v.Scan(src); err != nil {
+			return err
+		}
+		n.dst.SetBool(v.Bool)
+	case reflect.String:
+		v := sql.NullString{}
+		if err := v.Scan(src); err != nil {
+			return err
+		}
+		n.dst.SetString(v.String)
+	default:
+		if n.dst.Type() != reflect.TypeOf(time.Time{}) {
+			return fmt.Errorf("unsupported type %v to scan", n.dst.Type())
+		}
+		v := sql.NullTime{}
+		if err := v.Scan(src); err != nil {
+			return err
+		}
+		n.dst.Set(reflect.ValueOf(v.Time))
+	}
+	return nil
+}
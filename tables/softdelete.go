@@ -0,0 +1,90 @@
+package tables
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/zgljl2012/go-orm"
+	log "github.com/zgljl2012/slog"
+)
+
+// visibility of the soft deleted rows in a filter set
+const (
+	excludeDeleted = iota
+	withDeleted
+	onlyDeleted
+)
+
+// softDelete return the soft delete field, nil if there isn't
+func (t *simpleTable) softDelete() orm.Field {
+	for _, field := range t.fields {
+		if field.SoftDelete() {
+			return field
+		}
+	}
+	return nil
+}
+
+// setDeleted set the soft delete field of the row via primary keys, the row is restored if deletedAt is zero
+func (t *simpleTable) setDeleted(instance interface{}, field orm.Field, deletedAt time.Time) error {
+	p := newParams(t.dialect)
+	var value interface{}
+	if !deletedAt.IsZero() {
+		value = deletedAt
+	}
+	sql := "UPDATE " + t.quote(t.Name()) + " SET " + t.quote(field.Name()) + "=" + p.add(value)
+	primaryKeys, primaryValues := t.parseInstance(instance, true)
+	for i, key := range primaryKeys {
+		primaryKeys[i] = fmt.Sprintf("%s=%s", t.quote(key), p.add(primaryValues[i]))
+	}
+	sql += " WHERE " + strings.Join(primaryKeys, " AND ")
+
+	log.Debug(sql)
+
+	if _, err := t.exec(t.ctx, sql, p.values); err != nil {
+		log.Error("got an error when set deleted", "err", err)
+		return err
+	}
+	reflect.ValueOf(instance).Elem().FieldByName(field.ID()).Set(reflect.ValueOf(deletedAt))
+	return nil
+}
+
+// Restore clear the soft delete field of the row
+func (t *simpleTable) Restore(instance interface{}) error {
+	field := t.softDelete()
+	if field == nil {
+		return fmt.Errorf(ErrSoftDeleteNotExists)
+	}
+	return t.setDeleted(instance, field, time.Time{})
+}
+
+// WithDeleted include the soft deleted rows
+func (f *filterSet) WithDeleted() orm.FilterSet {
+	f.deleted = withDeleted
+	return f
+}
+
+// OnlyDeleted return only the soft deleted rows
+func (f *filterSet) OnlyDeleted() orm.FilterSet {
+	f.deleted = onlyDeleted
+	return f
+}
+
+// deletedScope render the condition which excludes or selects the soft deleted rows,
+// empty if the table has no soft delete field or the soft deleted rows are included
+func (f *filterSet) deletedScope(resolve resolver) (string, error) {
+	field := f.table.softDelete()
+	if field == nil || f.deleted == withDeleted {
+		return "", nil
+	}
+	column, err := resolve(field.ID())
+	if err != nil {
+		return "", err
+	}
+	if f.deleted == onlyDeleted {
+		return column + " IS NOT NULL", nil
+	}
+	return column + " IS NULL", nil
+}
@@ -0,0 +1,103 @@
+package tables_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+// Customer is a test table with soft delete
+type Customer struct {
+	ID        int       `name:"id" primaryKey:"true"`
+	Name      string    `name:"name" length:"20"`
+	DeletedAt time.Time `name:"deleted_at" softDelete:"true"`
+}
+
+func TestSoftDelete(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	table, err := tables.NewStructTagsTable(db, &Customer{}, tables.WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	customers := []Customer{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 3, Name: "c"}}
+	if err := table.AddMany(customers, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(f orm.FilterSet, expect int) {
+		t.Helper()
+		if n, err := f.Count(); err != nil || n != expect {
+			t.Errorf("expect %d rows, but got %d: %v", expect, n, err)
+		}
+	}
+
+	first := customers[0]
+	if err := table.Delete(&first); err != nil {
+		t.Fatal(err)
+	}
+	if !first.DeletedAt.Equal(now) {
+		t.Errorf("deleted time is not set: %v", first.DeletedAt)
+	}
+	count(table.Filter(), 2)
+	count(table.Filter().WithDeleted(), 3)
+	count(table.Filter().OnlyDeleted(), 1)
+	if n, err := table.Count(&first); err != nil || n != 0 {
+		t.Errorf("the deleted row should not be counted: %d, %v", n, err)
+	}
+	if err := table.Update(&first); err == nil {
+		t.Error("the deleted row should not be updated")
+	}
+	got := Customer{}
+	if err := table.Filter().OnlyDeleted().Get(&got); err != nil || got.ID != 1 || !got.DeletedAt.Equal(now) {
+		t.Errorf("got wrong deleted row %+v: %v", got, err)
+	}
+
+	if err := table.Restore(&first); err != nil {
+		t.Fatal(err)
+	}
+	if !first.DeletedAt.IsZero() {
+		t.Errorf("deleted time is not cleared: %v", first.DeletedAt)
+	}
+	count(table.Filter(), 3)
+
+	// bulk delete only marks the rows
+	if n, err := table.Filter(orm.WithParameter("ID__in", []int{1, 2})).Delete(); err != nil || n != 2 {
+		t.Errorf("expect 2 rows deleted, but got %d: %v", n, err)
+	}
+	count(table.Filter(), 1)
+	count(table.Filter().WithDeleted(), 3)
+
+	// upsert doesn't restore the soft deleted row
+	if err := table.Upsert(&Customer{ID: 2, Name: "B"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Filter(orm.WithParameter("ID", 2)).WithDeleted().Get(&got); err != nil || got.Name != "B" || got.DeletedAt.IsZero() {
+		t.Errorf("the upserted row should be still deleted %+v: %v", got, err)
+	}
+
+	if err := table.HardDelete(&first); err != nil {
+		t.Fatal(err)
+	}
+	count(table.Filter().WithDeleted(), 2)
+}
+
+func TestRestoreWithoutSoftDelete(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &Item{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Restore(&Item{ID: 1}); err == nil {
+		t.Error("restore should be rejected")
+	}
+}
@@ -10,7 +10,7 @@ import (
 )
 
 // NewStructTagsTable new a table with tags
-func NewStructTagsTable(db *sql.DB, instance interface{}) (orm.Table, error) {
+func NewStructTagsTable(db *sql.DB, instance interface{}, opts ...TableOption) (orm.Table, error) {
 	t := reflect.TypeOf(instance)
 	kind := t.Kind()
 	if kind != reflect.Ptr {
@@ -39,10 +39,5 @@ func NewStructTagsTable(db *sql.DB, instance interface{}) (orm.Table, error) {
 		return nil, fmt.Errorf("Not found any primary keys")
 	}
 
-	return &simpleTable{
-		fields: fields,
-		db:     db,
-		table:  instance,
-		name:   reflect.TypeOf(reflect.Indirect(reflect.ValueOf(instance)).Interface()).Name(),
-	}, nil
+	return newSimpleTable(db, instance, fields, opts...), nil
 }
@@ -295,7 +295,10 @@ func TestFilterSet(t *testing.T) {
 
 	// Filter
 	filter := table.Filter()
-	rows := filter.All()
+	rows, err := filter.All()
+	if err != nil {
+		t.Fatal(err)
+	}
 	id := 1
 	for _, row := range rows {
 		user := row.(User)
@@ -316,7 +319,10 @@ func TestFilterSet(t *testing.T) {
 
 	// validate
 	filter = table.Filter()
-	rows = filter.All()
+	rows, err = filter.All()
+	if err != nil {
+		t.Fatal(err)
+	}
 	id = 1
 	for _, row := range rows {
 		user := row.(User)
@@ -331,7 +337,10 @@ func TestFilterSet(t *testing.T) {
 
 	// filter with id=1
 	filter = table.Filter(orm.WithParameter("ID", 1))
-	rows = filter.All()
+	rows, err = filter.All()
+	if err != nil {
+		t.Fatal(err)
+	}
 	if len(rows) != 1 {
 		t.Error("You should only filter one row")
 	}
@@ -340,20 +349,38 @@ func TestFilterSet(t *testing.T) {
 		t.Errorf("Expect id is 1, but got %v", user1.ID)
 	}
 
+	// unknown field
+	if _, err := table.Filter(orm.WithParameter("Unknown", 1)).All(); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if _, err := table.Filter().OrderBy("-Unknown").All(); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+
 	// orderby
-	user1 = table.Filter().OrderBy("-ID").All()[0].(User)
+	rows, err = table.Filter().OrderBy("-ID").All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user1 = rows[0].(User)
 	if user1.ID != 10 {
 		t.Errorf("ID of this user should be 10, but got %v", user1.ID)
 	}
 
 	// limit
-	rows = table.Filter().Limit(5).All()
+	rows, err = table.Filter().Limit(5).All()
+	if err != nil {
+		t.Fatal(err)
+	}
 	if len(rows) != 5 {
 		t.Errorf("rows'cnt should be 5, but got %v", len(rows))
 	}
 
 	// offset
-	rows = table.Filter().Offset(2).All()
+	rows, err = table.Filter().Offset(2).All()
+	if err != nil {
+		t.Fatal(err)
+	}
 	if rows[0].(User).ID != 3 {
 		t.Errorf("expected 3, but got %v", rows[0].(User).ID)
 	}
@@ -1,10 +1,12 @@
 package tables
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/zgljl2012/go-orm"
 	log "github.com/zgljl2012/slog"
@@ -19,18 +21,83 @@ const (
 	ErrTableNotImplementModelFields = "table is not implement ModelFields, there are not found function: Fields() []orm.Field "
 	// ErrRowIsNotExists exists error
 	ErrRowIsNotExists = "The row not exists"
+	// ErrFieldNotExists the field is not found in the table
+	ErrFieldNotExists = "field not exists"
+	// ErrUnsupportedOperator the operator of query parameter is not supported
+	ErrUnsupportedOperator = "unsupported operator"
+	// ErrUnsupportedConnector the connector of condition group is not supported
+	ErrUnsupportedConnector = "unsupported connector"
+	// ErrInvalidValue the value of query parameter doesn't match its operator
+	ErrInvalidValue = "invalid value"
+	// ErrUnsupportedFunction the aggregate function is not supported
+	ErrUnsupportedFunction = "unsupported aggregate function"
+	// ErrNoAggregations nothing to select when aggregating
+	ErrNoAggregations = "no aggregations"
+	// ErrSlicedFilterSet bulk operations can't be applied to a filter set with limit or offset
+	ErrSlicedFilterSet = "can't update or delete a filter set with limit or offset"
+	// ErrInstancesShouldBeSlice instances should be a slice of the table struct or pointers of it
+	ErrInstancesShouldBeSlice = "instances should be a slice of the table struct"
+	// ErrDestinationType the destination to scan into is not the same type as the table
+	ErrDestinationType = "destination should be a pointer of the table struct"
+	// ErrRelationNotExists the relation field is not found in the table struct
+	ErrRelationNotExists = "relation not exists"
+	// ErrForeignKeyNotExists the foreign key of relation is not found or ambiguous
+	ErrForeignKeyNotExists = "foreign key not exists"
+	// ErrBulkJoin bulk operations can't filter across relations
+	ErrBulkJoin = "can't update or delete with conditions across relations"
+	// ErrCompositePrimaryKey many-to-many relations need tables with exactly one primary key
+	ErrCompositePrimaryKey = "table should have exactly one primary key"
+	// ErrInstanceType the instance is not the struct of table or a pointer of it
+	ErrInstanceType = "instance should be the table struct or a pointer of it"
+	// ErrPrimaryKeyNotExists the table has no primary keys
+	ErrPrimaryKeyNotExists = "table has no primary keys"
+	// ErrFieldType the type of struct field doesn't match the field options
+	ErrFieldType = "unsupported type of field"
+	// ErrConflictKeys conflicts can't be checked without keys
+	ErrConflictKeys = "conflict keys are required, declare primary keys or use orm.OnConflict"
+	// ErrUpsertVersion upsert can't check the version field
+	ErrUpsertVersion = "can't upsert a table with version field, use Add and Update instead"
+	// ErrSoftDeleteNotExists the table has no soft delete field
+	ErrSoftDeleteNotExists = "table has no soft delete field"
 )
 
 type simpleTable struct {
-	db     *sql.DB
-	fields []orm.Field
-	table  interface{}
-	name   string
+	ctx     context.Context
+	db      *sql.DB
+	tx      *sql.Tx
+	dialect orm.Dialect
+	fields  []orm.Field
+	table   interface{}
+	name    string
+	clock   func() time.Time
+}
+
+func newSimpleTable(db *sql.DB, table interface{}, fields []orm.Field, opts ...TableOption) *simpleTable {
+	options := defaultOptions()
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	name := options.Name
+	if name == "" {
+		name = reflect.TypeOf(reflect.Indirect(reflect.ValueOf(table)).Interface()).Name()
+	}
+
+	return &simpleTable{
+		ctx:     context.Background(),
+		db:      db,
+		dialect: options.Dialect,
+		fields:  fields,
+		table:   table,
+		name:    name,
+		clock:   options.Clock,
+	}
 }
 
 // NewTable create a table instance, you can input every struct.
 // All pub fields will be checked if their type is orm.Field.
-func NewTable(db *sql.DB, table interface{}) (orm.Table, error) {
+func NewTable(db *sql.DB, table interface{}, opts ...TableOption) (orm.Table, error) {
 	t := reflect.TypeOf(table)
 	kind := t.Kind()
 	log.Debug("table", "type", t, "kind", kind, "ptrTo", reflect.Indirect(reflect.ValueOf(table)).Kind())
@@ -44,31 +111,112 @@ func NewTable(db *sql.DB, table interface{}) (orm.Table, error) {
 	if !t.Implements(reflect.TypeOf((*orm.ModelFields)(nil)).Elem()) {
 		return nil, fmt.Errorf(ErrTableNotImplementModelFields)
 	}
-	return &simpleTable{
-		db:     db,
-		table:  table,
-		fields: table.(orm.ModelFields).Fields(),
-		name:   reflect.TypeOf(reflect.Indirect(reflect.ValueOf(table)).Interface()).Name(),
-	}, nil
+	fields := table.(orm.ModelFields).Fields()
+	if err := checkFields(table, fields); err != nil {
+		return nil, err
+	}
+	return newSimpleTable(db, table, fields, opts...), nil
 }
 
-func (t *simpleTable) Create(skipIfExists bool) error {
+// checkFields check the types of struct fields which are bound to the fields with specific options,
+// fields which aren't in the struct are skipped
+func checkFields(table interface{}, fields []orm.Field) error {
+	typ := reflect.TypeOf(table).Elem()
+	for _, field := range fields {
+		f, ok := typ.FieldByName(field.ID())
+		if !ok {
+			continue
+		}
+		if field.AutoIncrement() && !isInteger(f.Type.Kind()) {
+			return fmt.Errorf(`%s: auto increment field "%s" should be an integer`, ErrFieldType, field.ID())
+		}
+		if field.Version() && !isInteger(f.Type.Kind()) {
+			return fmt.Errorf(`%s: version field "%s" should be an integer`, ErrFieldType, field.ID())
+		}
+		if (field.AutoCreateTime() || field.AutoUpdateTime()) && f.Type != reflect.TypeOf(time.Time{}) {
+			return fmt.Errorf(`%s: auto time field "%s" should be a time.Time`, ErrFieldType, field.ID())
+		}
+	}
+	return nil
+}
+
+// isInteger report whether kind is a signed or unsigned integer
+func isInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// setInteger set n to the integer value, unsigned or not
+func setInteger(value reflect.Value, n int64) {
+	switch value.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value.SetUint(uint64(n))
+	default:
+		value.SetInt(n)
+	}
+}
+
+// columnDefinition render the column of field in CREATE TABLE and ALTER TABLE
+func (t *simpleTable) columnDefinition(field orm.Field) string {
+	sql := fmt.Sprintf(`%s %s`, t.quote(field.Name()), t.dialect.DataType(field))
+	if field.Null() {
+		sql += " NULL"
+	} else {
+		sql += " NOT NULL"
+	}
+	if value := field.Default(); value != nil {
+		sql += " DEFAULT " + literal(value)
+	}
+	return sql
+}
+
+// literal render the value in DDL
+func literal(value interface{}) string {
+	switch v := value.(type) {
+	case orm.Expr:
+		return string(v)
+	case string:
+		return "'" + strings.Replace(v, "'", "''", -1) + "'"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		return "'" + v.UTC().Format("2006-01-02 15:04:05") + "'"
+	}
+	return fmt.Sprint(value)
+}
+
+// createSQL build the CREATE TABLE statement with the name
+func (t *simpleTable) createSQL(name string, skipIfExists bool) string {
 	var primaryKeys []string
 	sql := "CREATE TABLE "
 	if skipIfExists {
-		sql += " IF NOT EXISTS "
+		sql += "IF NOT EXISTS "
 	}
-	sql += t.Name()
+	sql += t.quote(name)
 	sql += `(`
 	// iterate fields
 	for i, field := range t.fields {
-		log.Debug("iterare field", "table", t.Name(), "field", field.Name(), "type", field.Type())
-		sql += fmt.Sprintf(`%s %s`, field.Name(), field.Type())
+		log.Debug("iterare field", "table", name, "field", field.Name(), "type", field.Type())
+		declared := false
+		if field.AutoIncrement() {
+			var definition string
+			definition, declared = t.dialect.AutoIncrement(field)
+			sql += t.quote(field.Name()) + " " + definition
+		} else {
+			sql += t.columnDefinition(field)
+		}
 		if i < len(t.fields)-1 {
 			sql += ","
 		}
-		if field.PrimaryKey() {
-			primaryKeys = append(primaryKeys, field.Name())
+		if field.PrimaryKey() && !declared {
+			primaryKeys = append(primaryKeys, t.quote(field.Name()))
 		}
 	}
 	// primary keys
@@ -77,75 +225,390 @@ func (t *simpleTable) Create(skipIfExists bool) error {
 		sql += strings.Join(primaryKeys, ",")
 		sql += ")"
 	}
-	sql += `)`
-	log.Info(sql)
-	if _, err := t.db.Exec(sql); err != nil {
-		return err
+	// foreign keys
+	for _, field := range t.fields {
+		if fk := field.ForeignKey(); fk != nil {
+			sql += ", FOREIGN KEY(" + t.quote(field.Name()) + ") REFERENCES " + t.quote(fk.Table) + "(" + t.quote(fk.Column) + ")"
+			if fk.OnDelete != "" {
+				sql += " ON DELETE " + fk.OnDelete
+			}
+		}
 	}
-	return nil
+	sql += `)`
+	return sql
+}
+
+// Create the table and its indexes, the existing indexes are skipped
+func (t *simpleTable) Create(skipIfExists bool) error {
+	return t.transaction(t.ctx, func(tx executor) error {
+		if err := t.execDDL(tx, t.createSQL(t.Name(), skipIfExists)); err != nil {
+			return err
+		}
+		if _, err := t.createIndexes(tx); err != nil {
+			return err
+		}
+		return t.createJoinTables(tx)
+	})
 }
 
 func (t *simpleTable) Name() string {
 	return t.name
 }
 
-func (t *simpleTable) exec(sql string, values []interface{}) error {
-	tx, err := t.db.Begin()
-	if err != nil {
-		return err
+// primaryKeys return the names of primary keys
+func (t *simpleTable) primaryKeys() []string {
+	keys := []string{}
+	for _, field := range t.fields {
+		if field.PrimaryKey() {
+			keys = append(keys, field.Name())
+		}
 	}
-	stmt, err := tx.Prepare(sql)
+	return keys
+}
+
+// columns return the quoted columns of all fields joined with comma
+func (t *simpleTable) columns() string {
+	columns := []string{}
+	for _, field := range t.fields {
+		columns = append(columns, t.quote(field.Name()))
+	}
+	return strings.Join(columns, ",")
+}
+
+// column return the quoted column of the field
+func (t *simpleTable) column(name string) (string, error) {
+	field, err := t.field(name)
 	if err != nil {
-		return err
+		return "", err
+	}
+	return t.quote(field.Name()), nil
+}
+
+// WithContext return a copy of the table whose operations run with ctx
+func (t *simpleTable) WithContext(ctx context.Context) orm.Table {
+	table := *t
+	table.ctx = ctx
+	return &table
+}
+
+// WithTx return a copy of the table whose operations run inside tx
+func (t *simpleTable) WithTx(tx *sql.Tx) orm.Table {
+	table := *t
+	table.tx = tx
+	return &table
+}
+
+func (t *simpleTable) quote(identifier string) string {
+	return t.dialect.Quote(identifier)
+}
+
+// field find the field by name, name can be either the name in struct or the column name
+func (t *simpleTable) field(name string) (orm.Field, error) {
+	for _, field := range t.fields {
+		if field.ID() == name || field.Name() == name {
+			return field, nil
+		}
+	}
+	return nil, fmt.Errorf(`%s: "%s"`, ErrFieldNotExists, name)
+}
+
+// executor is implemented by both *sql.DB and *sql.Tx
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// executor return the bound transaction, or the database if not bound
+func (t *simpleTable) executor() executor {
+	if t.tx != nil {
+		return t.tx
 	}
-	if _, err := stmt.Exec(values...); err != nil {
+	return t.db
+}
+
+// transaction run fn inside the bound transaction,
+// or a new transaction which is committed if fn succeeds and rolled back if not
+func (t *simpleTable) transaction(ctx context.Context, fn func(tx executor) error) error {
+	if t.tx != nil {
+		return fn(t.tx)
+	}
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
 		return err
 	}
-	if err := tx.Commit(); err != nil {
+	if err := fn(tx); err != nil {
+		if err := tx.Rollback(); err != nil {
+			log.Error("got an error when rollback", "err", err)
+		}
 		return err
 	}
-	if err := stmt.Close(); err != nil {
+	return tx.Commit()
+}
+
+// scanner is implemented by *sql.Rows and *sql.Row
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// query run the query and pass the rows to fn one by one
+func (t *simpleTable) query(ctx context.Context, query string, values []interface{}, fn func(row scanner) error) error {
+	return t.transaction(ctx, func(tx executor) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		rows, err := stmt.QueryContext(ctx, values...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := fn(rows); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+func (t *simpleTable) exec(ctx context.Context, sql string, values []interface{}) (result sql.Result, err error) {
+	err = t.transaction(ctx, func(tx executor) error {
+		stmt, err := tx.PrepareContext(ctx, sql)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		result, err = stmt.ExecContext(ctx, values...)
 		return err
+	})
+	return result, err
+}
+
+// autoIncrement return the auto increment field, nil if there isn't
+func (t *simpleTable) autoIncrement() orm.Field {
+	for _, field := range t.fields {
+		if field.AutoIncrement() {
+			return field
+		}
 	}
 	return nil
 }
 
-// Add
-func (t *simpleTable) Add(instance interface{}) error {
-	sql := "INSERT INTO " + t.Name() + " ("
-	// fields
-	names, values := t.parseInstance(instance, false)
-	params := []string{}
-	for range names {
-		params = append(params, "?")
+// generated return the auto increment field if its value of instance is zero, which will be generated
+func (t *simpleTable) generated(instance interface{}) orm.Field {
+	field := t.autoIncrement()
+	if field == nil || !reflect.ValueOf(instance).Elem().FieldByName(field.ID()).IsZero() {
+		return nil
 	}
-	sql += strings.Join(names, ",")
-	sql += ") VALUES ("
+	return field
+}
+
+// fillDefaults set the zero fields of instance with their default funcs,
+// static defaults are left to the database
+func (t *simpleTable) fillDefaults(instance interface{}) {
+	obj := reflect.ValueOf(instance).Elem()
+	for _, field := range t.fields {
+		value := obj.FieldByName(field.ID())
+		fn := field.DefaultFunc()
+		if fn == nil || !value.IsZero() {
+			continue
+		}
+		v := fn()
+		if v == nil {
+			continue
+		}
+		if rv := reflect.ValueOf(v); rv.Type().ConvertibleTo(value.Type()) {
+			value.Set(rv.Convert(value.Type()))
+		}
+	}
+}
+
+// stamp set the auto time fields of instance with the current time,
+// the auto create time fields are only set when they are zero on adding
+func (t *simpleTable) stamp(instance interface{}, adding bool) {
+	obj := reflect.ValueOf(instance).Elem()
+	now := reflect.ValueOf(t.clock())
+	for _, field := range t.fields {
+		value := obj.FieldByName(field.ID())
+		if field.AutoUpdateTime() && (!adding || value.IsZero()) {
+			value.Set(now)
+		} else if field.AutoCreateTime() && adding && value.IsZero() {
+			value.Set(now)
+		}
+	}
+}
+
+// omitted return the columns which are generated by the database if they are zero in all instances,
+// they are the auto increment field and the fields with defaults
+func (t *simpleTable) omitted(instances []interface{}) map[string]bool {
+	omitted := map[string]bool{}
+	for _, field := range t.fields {
+		if field.Default() == nil && !field.AutoIncrement() {
+			continue
+		}
+		zero := true
+		for _, instance := range instances {
+			if !reflect.ValueOf(instance).Elem().FieldByName(field.ID()).IsZero() {
+				zero = false
+				break
+			}
+		}
+		omitted[field.Name()] = zero
+	}
+	return omitted
+}
+
+// insertSQL build the INSERT statement of instances, one row per instance.
+// The zero fields of instances are filled with their defaults first,
+// and the columns generated by the database are omitted if they are zero.
+func (t *simpleTable) insertSQL(instances ...interface{}) (string, *params) {
+	sql := "INSERT INTO " + t.quote(t.Name()) + " ("
+	p := newParams(t.dialect)
+	for _, instance := range instances {
+		t.stamp(instance, true)
+		t.fillDefaults(instance)
+	}
+	omitted := t.omitted(instances)
+	rows := []string{}
+	columns := []string{}
+	for _, instance := range instances {
+		names, values := t.parseInstance(instance, false)
+		columns = columns[:0]
+		placeholders := []string{}
+		for i, value := range values {
+			if omitted[names[i]] {
+				continue
+			}
+			columns = append(columns, t.quote(names[i]))
+			placeholders = append(placeholders, p.add(value))
+		}
+		rows = append(rows, "("+strings.Join(placeholders, ",")+")")
+	}
+	// fields
+	sql += strings.Join(columns, ",")
+	sql += ") VALUES "
 	// values
-	sql += strings.Join(params, ",")
-	sql += ")"
+	sql += strings.Join(rows, ",")
+	return sql, p
+}
 
-	log.Debug(sql)
+// Add insert the instance, the generated auto increment id is written back to it
+func (t *simpleTable) Add(instance interface{}) error {
+	field := t.generated(instance)
+	sql, p := t.insertSQL(instance)
+	if field == nil {
+		log.Debug(sql)
+		if _, err := t.exec(t.ctx, sql, p.values); err != nil {
+			log.Error("got an error when add data", "err", err)
+			return err
+		}
+		return nil
+	}
 
-	if err := t.exec(sql, values); err != nil {
-		log.Error("got an error when add data", "err", err)
-		return err
+	var id int64
+	if clause := t.dialect.Returning(field.Name()); clause != "" {
+		sql += " " + clause
+		log.Debug(sql)
+		err := t.query(t.ctx, sql, p.values, func(row scanner) error {
+			return row.Scan(&id)
+		})
+		if err != nil {
+			log.Error("got an error when add data", "err", err)
+			return err
+		}
+	} else {
+		log.Debug(sql)
+		result, err := t.exec(t.ctx, sql, p.values)
+		if err != nil {
+			log.Error("got an error when add data", "err", err)
+			return err
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
 	}
+	setInteger(reflect.ValueOf(instance).Elem().FieldByName(field.ID()), id)
 	return nil
 }
 
+// AddMany insert a slice of instances with multi-row INSERT statements in one transaction,
+// every statement inserts at most batchSize rows, and is also limited by the max parameters of the database.
+// A non-positive batchSize means as many rows as the database allows. Adjacent rows are split into
+// different statements if the columns generated by the database are zero in some of them but not in others.
+func (t *simpleTable) AddMany(instances interface{}, batchSize int) error {
+	value := reflect.ValueOf(instances)
+	if value.Kind() != reflect.Slice {
+		return fmt.Errorf(ErrInstancesShouldBeSlice)
+	}
+	// collect pointers of instances
+	rows := make([]interface{}, value.Len())
+	for i := range rows {
+		item := value.Index(i)
+		if item.Kind() != reflect.Ptr {
+			item = item.Addr()
+		}
+		if item.Type() != reflect.TypeOf(t.table) {
+			return fmt.Errorf(ErrInstancesShouldBeSlice)
+		}
+		rows[i] = item.Interface()
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	// batch size is limited by the max parameters
+	if limit := t.dialect.MaxParameters() / len(t.fields); batchSize <= 0 || batchSize > limit {
+		batchSize = limit
+	}
+	// fill the rows before splitting, since the omitted columns depend on the filled values
+	for _, row := range rows {
+		t.stamp(row, true)
+		t.fillDefaults(row)
+	}
+	return t.transaction(t.ctx, func(tx executor) error {
+		for start, batch := 0, 0; start < len(rows); batch++ {
+			end := start + 1
+			for end < len(rows) && end-start < batchSize &&
+				reflect.DeepEqual(t.omitted(rows[start:start+1]), t.omitted(rows[end:end+1])) {
+				end++
+			}
+			sql, p := t.insertSQL(rows[start:end]...)
+
+			log.Debug(sql)
+
+			if _, err := tx.ExecContext(t.ctx, sql, p.values...); err != nil {
+				log.Error("got an error when add data", "batch", batch, "err", err)
+				return fmt.Errorf("batch %d (rows %d-%d) failed: %v", batch, start, end-1, err)
+			}
+			start = end
+		}
+		return nil
+	})
+}
+
 // Delete
 func (t *simpleTable) Delete(instance interface{}) error {
+	if field := t.softDelete(); field != nil {
+		return t.setDeleted(instance, field, t.clock())
+	}
+	return t.HardDelete(instance)
+}
+
+// HardDelete delete the row via primary keys even if the table has a soft delete field
+func (t *simpleTable) HardDelete(instance interface{}) error {
 	// get primary keys
 	primaryKeys, primaryValues := t.parseInstance(instance, true)
+	p := newParams(t.dialect)
 	for i, key := range primaryKeys {
-		primaryKeys[i] = fmt.Sprintf("%s=?", key)
+		primaryKeys[i] = fmt.Sprintf("%s=%s", t.quote(key), p.add(primaryValues[i]))
 	}
-	sql := "DELETE FROM " + t.Name() + " WHERE " + strings.Join(primaryKeys, ",")
+	sql := "DELETE FROM " + t.quote(t.Name()) + " WHERE " + strings.Join(primaryKeys, " AND ")
 
 	log.Debug(sql)
 
-	if err := t.exec(sql, primaryValues); err != nil {
+	if _, err := t.exec(t.ctx, sql, p.values); err != nil {
 		log.Error("got an error when delete data", "err", err)
 		return err
 	}
@@ -162,6 +625,10 @@ func (t *simpleTable) parseInstance(instance interface{}, justPrimaryKeys bool)
 		if !justPrimaryKeys || field.PrimaryKey() {
 			names = append(names, field.Name())
 			value := reflect.ValueOf(instance).Elem().FieldByName(field.ID()).Interface()
+			if field.SoftDelete() && reflect.ValueOf(value).IsZero() {
+				// the row isn't deleted
+				value = nil
+			}
 			values = append(values, value)
 			params = append(params, "?")
 		}
@@ -182,28 +649,26 @@ func (t *simpleTable) Exists(instance interface{}) error {
 
 func (t *simpleTable) Count(instance interface{}) (int, error) {
 	names, values := t.parseInstance(instance, true)
-	sql := "SELECT COUNT(*) FROM " + t.Name() + " WHERE "
+	sql := "SELECT COUNT(*) FROM " + t.quote(t.Name()) + " WHERE "
+	p := newParams(t.dialect)
 	for i, name := range names {
-		names[i] = fmt.Sprintf("%s=?", name)
+		names[i] = fmt.Sprintf("%s=%s", t.quote(name), p.add(values[i]))
 	}
 	sql += strings.Join(names, " AND ")
-	log.Debug(sql)
-	tx, err := t.db.Begin()
-	if err != nil {
-		return 0, err
-	}
-	stmt, err := tx.Prepare(sql)
-	if err != nil {
-		return 0, err
+	if field := t.softDelete(); field != nil {
+		sql += " AND " + t.quote(field.Name()) + " IS NULL"
 	}
+	log.Debug(sql)
 	cnt := 0
-	if err := stmt.QueryRow(values...).Scan(&cnt); err != nil {
-		return 0, err
-	}
-	if err := tx.Commit(); err != nil {
-		return 0, err
-	}
-	if err := stmt.Close(); err != nil {
+	err := t.transaction(t.ctx, func(tx executor) error {
+		stmt, err := tx.PrepareContext(t.ctx, sql)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		return stmt.QueryRowContext(t.ctx, p.values...).Scan(&cnt)
+	})
+	if err != nil {
 		return 0, err
 	}
 	return cnt, nil
@@ -215,42 +680,77 @@ func (t *simpleTable) Update(instance interface{}) error {
 	if err := t.Exists(instance); err != nil {
 		return err
 	}
-	// get primary keys
-	primaryKeys, primaryValues := t.parseInstance(instance, true)
-	for i, key := range primaryKeys {
-		primaryKeys[i] = fmt.Sprintf("%s=?", key)
+	t.stamp(instance, false)
+	p := newParams(t.dialect)
+
+	// the version is increased
+	version := t.version()
+	var current, next reflect.Value
+	if version != nil {
+		current = reflect.ValueOf(instance).Elem().FieldByName(version.ID())
+		next = reflect.New(current.Type()).Elem()
+		switch current.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			next.SetUint(current.Uint() + 1)
+		default:
+			next.SetInt(current.Int() + 1)
+		}
 	}
 
 	// keys, values
 	names, values := t.parseInstance(instance, false)
 
 	for i, name := range names {
-		names[i] = fmt.Sprintf("%s=?", name)
+		if version != nil && name == version.Name() {
+			values[i] = next.Interface()
+		}
+		names[i] = fmt.Sprintf("%s=%s", t.quote(name), p.add(values[i]))
+	}
+
+	// get primary keys
+	primaryKeys, primaryValues := t.parseInstance(instance, true)
+	for i, key := range primaryKeys {
+		primaryKeys[i] = fmt.Sprintf("%s=%s", t.quote(key), p.add(primaryValues[i]))
+	}
+	if version != nil {
+		primaryKeys = append(primaryKeys, fmt.Sprintf("%s=%s", t.quote(version.Name()), p.add(current.Interface())))
 	}
 
 	// sql
-	sql := "UPDATE " + t.Name() + " SET "
+	sql := "UPDATE " + t.quote(t.Name()) + " SET "
 	sql += strings.Join(names, ",")
 	sql += " WHERE " + strings.Join(primaryKeys, " AND ")
 
 	log.Debug(sql)
 
-	if err := t.exec(sql, append(values, primaryValues...)); err != nil {
+	result, err := t.exec(t.ctx, sql, p.values)
+	if err != nil {
 		log.Error("got an error when update data", "err", err)
 		return err
 	}
+	if version == nil {
+		return nil
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return orm.ErrStaleObject
+	}
+	current.Set(next)
 	return nil
 }
 
-func (t *simpleTable) Filter(filters ...*orm.QueryParameter) orm.FilterSet {
-	// validate parameters
-	return newFilterSet(t.db, t.Name(), t.table, t.fields).Filter(filters...)
+// version return the version field, nil if there isn't
+func (t *simpleTable) version() orm.Field {
+	for _, field := range t.fields {
+		if field.Version() {
+			return field
+		}
+	}
+	return nil
 }
 
-func (t *simpleTable) Upsert(instance interface{}) error {
-	// check the row exists or not
-	if err := t.Exists(instance); err == nil {
-		return t.Update(instance)
-	}
-	return t.Add(instance)
+func (t *simpleTable) Filter(filters ...orm.Condition) orm.FilterSet {
+	// validate parameters
+	return newFilterSet(t).Filter(filters...)
 }
@@ -0,0 +1,153 @@
+package tables
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/zgljl2012/go-orm"
+	log "github.com/zgljl2012/slog"
+)
+
+// clone copy the filter set, so that the terminal helpers won't change it
+func (f *filterSet) clone() *filterSet {
+	c := *f
+	c.order = append([]string{}, f.order...)
+	c.preload = append([]string{}, f.preload...)
+	c.scopes = append([]scope{}, f.scopes...)
+	return &c
+}
+
+// Count return the number of filtered rows
+func (f *filterSet) Count() (int, error) {
+	t := f.table
+	p := newParams(t.dialect)
+	j, err := f.joins(nil)
+	if err != nil {
+		return 0, err
+	}
+	sql := "SELECT COUNT(*) FROM " + j.from()
+	if f.limit > 0 || f.offset > 0 {
+		// count the sliced rows
+		query, subParams, err := f.selectSQL()
+		if err != nil {
+			return 0, err
+		}
+		p = subParams
+		sql = "SELECT COUNT(*) FROM (" + query + ") AS " + t.quote("sliced")
+	} else {
+		where, err := f.where(p, j.column)
+		if err != nil {
+			return 0, err
+		}
+		if where != "" {
+			sql += " WHERE " + where
+		}
+	}
+	log.Debug(sql)
+	cnt := 0
+	err = t.query(f.ctx, sql, p.values, func(row scanner) error {
+		return row.Scan(&cnt)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return cnt, nil
+}
+
+// Exists return true if any row matches
+func (f *filterSet) Exists() (bool, error) {
+	t := f.table
+	p := newParams(t.dialect)
+	j, err := f.joins(nil)
+	if err != nil {
+		return false, err
+	}
+	sql := "SELECT 1 FROM " + j.from()
+	where, err := f.where(p, j.column)
+	if err != nil {
+		return false, err
+	}
+	if where != "" {
+		sql += " WHERE " + where
+	}
+	sql += " " + t.dialect.LimitOffset(1, 0)
+	log.Debug(sql)
+	exists := false
+	err = t.query(f.ctx, sql, p.values, func(row scanner) error {
+		exists = true
+		return nil
+	})
+	return exists, err
+}
+
+// First scan the first row into dst
+func (f *filterSet) First(dst interface{}) error {
+	c := f.clone()
+	if len(c.order) == 0 {
+		c.order = c.table.primaryKeys()
+	}
+	c.limit = 1
+	return c.one(dst)
+}
+
+// Last scan the last row into dst
+func (f *filterSet) Last(dst interface{}) error {
+	c := f.clone()
+	if len(c.order) == 0 {
+		c.order = c.table.primaryKeys()
+	}
+	// reverse ordering
+	for i, order := range c.order {
+		order = strings.Trim(order, " ")
+		if strings.HasPrefix(order, "-") {
+			c.order[i] = order[1:]
+		} else {
+			c.order[i] = "-" + order
+		}
+	}
+	c.limit = 1
+	return c.one(dst)
+}
+
+// Get scan the only matched row into dst
+func (f *filterSet) Get(dst interface{}) error {
+	c := f.clone()
+	// one more row is enough to know there are multiple rows
+	c.limit = 2
+	return c.one(dst)
+}
+
+// one scan the first row into dst, return ErrMultipleRows if there are more rows
+func (f *filterSet) one(dst interface{}) error {
+	t := f.table
+	if reflect.TypeOf(dst) != reflect.TypeOf(t.table) {
+		return fmt.Errorf(ErrDestinationType)
+	}
+	sql, p, err := f.selectSQL()
+	if err != nil {
+		return err
+	}
+	log.Debug(sql)
+	cnt := 0
+	// dst is untouched unless exactly one row is scanned
+	obj := reflect.New(reflect.TypeOf(t.table).Elem()).Elem()
+	err = t.query(f.ctx, sql, p.values, func(row scanner) error {
+		cnt++
+		if cnt > 1 {
+			return orm.ErrMultipleRows
+		}
+		return row.Scan(f.scanDest(obj)...)
+	})
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return orm.ErrNotFound
+	}
+	if err := f.preloadRelations([]reflect.Value{obj}); err != nil {
+		return err
+	}
+	reflect.ValueOf(dst).Elem().Set(obj)
+	return nil
+}
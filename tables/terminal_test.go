@@ -0,0 +1,87 @@
+package tables_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+func TestTerminalHelpers(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Create(true); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing
+	user := User{}
+	if err := table.Filter().First(&user); err != orm.ErrNotFound {
+		t.Errorf("expect %v, but got %v", orm.ErrNotFound, err)
+	}
+	if exists, err := table.Filter().Exists(); err != nil || exists {
+		t.Errorf("expect not exists, but got %v, err: %v", exists, err)
+	}
+
+	for i := 1; i <= 10; i++ {
+		if err := table.Add(&User{ID: i, Username: fmt.Sprintf("user%d", i), Age: float32(100 - i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// count
+	if cnt, err := table.Filter(orm.WithParameter("ID__gt", 3)).Count(); err != nil || cnt != 7 {
+		t.Errorf("expect 7, but got %v, err: %v", cnt, err)
+	}
+	if cnt, err := table.Filter().Offset(8).Count(); err != nil || cnt != 2 {
+		t.Errorf("expect 2, but got %v, err: %v", cnt, err)
+	}
+
+	// exists
+	if exists, err := table.Filter(orm.WithParameter("username", "user3")).Exists(); err != nil || !exists {
+		t.Errorf("expect exists, but got %v, err: %v", exists, err)
+	}
+
+	// first, last
+	filter := table.Filter(orm.WithParameter("ID__lte", 5))
+	if err := filter.First(&user); err != nil || user.ID != 1 {
+		t.Errorf("expect 1, but got %v, err: %v", user.ID, err)
+	}
+	if err := filter.Last(&user); err != nil || user.ID != 5 {
+		t.Errorf("expect 5, but got %v, err: %v", user.ID, err)
+	}
+	if err := table.Filter().OrderBy("age").First(&user); err != nil || user.ID != 10 {
+		t.Errorf("expect 10, but got %v, err: %v", user.ID, err)
+	}
+	if err := table.Filter().OrderBy("age").Last(&user); err != nil || user.ID != 1 {
+		t.Errorf("expect 1, but got %v, err: %v", user.ID, err)
+	}
+	// the filter set is not changed by the helpers
+	if rows, err := filter.All(); err != nil || len(rows) != 5 {
+		t.Errorf("expect 5 rows, but got %v, err: %v", len(rows), err)
+	}
+
+	// get
+	if err := table.Filter(orm.WithParameter("username", "user7")).Get(&user); err != nil || user.ID != 7 {
+		t.Errorf("expect 7, but got %v, err: %v", user.ID, err)
+	}
+	if err := table.Filter(orm.WithParameter("username", "nobody")).Get(&user); err != orm.ErrNotFound {
+		t.Errorf("expect %v, but got %v", orm.ErrNotFound, err)
+	}
+	if err := table.Filter(orm.WithParameter("ID__in", []int{1, 2})).Get(&user); err != orm.ErrMultipleRows {
+		t.Errorf("expect %v, but got %v", orm.ErrMultipleRows, err)
+	}
+	if user.ID != 7 {
+		t.Errorf("dst should be untouched, but got %v", user.ID)
+	}
+	if err := table.Filter().First(user); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+}
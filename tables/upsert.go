@@ -0,0 +1,187 @@
+package tables
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/zgljl2012/go-orm"
+	log "github.com/zgljl2012/slog"
+)
+
+// upsertColumns resolve the conflict target and the columns to update from options
+func (t *simpleTable) upsertColumns(options orm.UpsertOptions) ([]string, []string, error) {
+	keys := t.primaryKeys()
+	if len(options.Conflict) > 0 {
+		keys = []string{}
+		for _, name := range options.Conflict {
+			field, err := t.field(name)
+			if err != nil {
+				return nil, nil, err
+			}
+			keys = append(keys, field.Name())
+		}
+	}
+	excluded := map[string]bool{}
+	for _, key := range keys {
+		excluded[key] = true
+	}
+	for _, name := range options.Keep {
+		field, err := t.field(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		excluded[field.Name()] = true
+	}
+	candidates := options.Update
+	if candidates == nil {
+		// the id and the creation time of the existing row are kept, and soft deleted rows are only restored by Restore
+		for _, field := range t.fields {
+			if !field.AutoIncrement() && !field.AutoCreateTime() && !field.SoftDelete() {
+				candidates = append(candidates, field.Name())
+			}
+		}
+	}
+	updates := []string{}
+	for _, name := range candidates {
+		field, err := t.field(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !excluded[field.Name()] {
+			updates = append(updates, field.Name())
+		}
+	}
+	return keys, updates, nil
+}
+
+// upsert insert the instance, the clause decides what to do when keys conflict.
+// The generated id of the inserted or updated row is written back, it's left zero if the row is ignored.
+func (t *simpleTable) upsert(instance interface{}, keys []string, clause string) error {
+	field := t.generated(instance)
+	sql, p := t.insertSQL(instance)
+	sql += " " + clause
+
+	var id int64
+	returning := ""
+	if field != nil {
+		returning = t.dialect.Returning(field.Name())
+	}
+	if returning != "" {
+		sql += " " + returning
+		log.Debug(sql)
+		// no rows are returned if the row is ignored
+		err := t.query(t.ctx, sql, p.values, func(row scanner) error {
+			return row.Scan(&id)
+		})
+		if err != nil {
+			log.Error("got an error when upsert data", "err", err)
+			return err
+		}
+	} else {
+		log.Debug(sql)
+		result, err := t.exec(t.ctx, sql, p.values)
+		if err != nil {
+			log.Error("got an error when upsert data", "err", err)
+			return err
+		}
+		if field == nil {
+			return nil
+		}
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+		// LastInsertId isn't reported for the updated row, read the id by the keys
+		if id, err = t.keyID(instance, field, keys); err != nil {
+			return err
+		}
+		if id == 0 {
+			if id, err = result.LastInsertId(); err != nil {
+				return err
+			}
+		}
+	}
+	if id != 0 {
+		setInteger(reflect.ValueOf(instance).Elem().FieldByName(field.ID()), id)
+	}
+	return nil
+}
+
+// keyID read the id of the row which has the same keys as instance, zero if there isn't
+func (t *simpleTable) keyID(instance interface{}, field orm.Field, keys []string) (int64, error) {
+	names, values := t.parseInstance(instance, false)
+	p := newParams(t.dialect)
+	conditions := []string{}
+	for _, key := range keys {
+		if key == field.Name() {
+			// the generated key is omitted from INSERT, so the row is inserted
+			return 0, nil
+		}
+		for i, name := range names {
+			if name == key {
+				conditions = append(conditions, t.quote(key)+"="+p.add(values[i]))
+			}
+		}
+	}
+	sql := "SELECT " + t.quote(field.Name()) + " FROM " + t.quote(t.Name()) + " WHERE " + strings.Join(conditions, " AND ")
+
+	log.Debug(sql)
+
+	var id int64
+	err := t.query(t.ctx, sql, p.values, func(row scanner) error {
+		return row.Scan(&id)
+	})
+	return id, err
+}
+
+// Upsert add or update in one statement, it's rejected if the table has a version field,
+// since the conflict update can't check the version
+func (t *simpleTable) Upsert(instance interface{}, opts ...orm.UpsertOption) error {
+	if t.version() != nil {
+		return fmt.Errorf(ErrUpsertVersion)
+	}
+	options := orm.UpsertOptions{}
+	for _, o := range opts {
+		o(&options)
+	}
+	keys, updates, err := t.upsertColumns(options)
+	if err != nil {
+		return err
+	}
+	t.stamp(instance, false)
+	// nothing to update
+	if len(updates) == 0 {
+		return t.AddOrIgnore(instance, opts...)
+	}
+	if clause := t.dialect.OnConflict(keys, updates); clause != "" {
+		return t.upsert(instance, keys, clause)
+	}
+	// the database can't upsert natively, check the row exists or not
+	if err := t.Exists(instance); err == nil {
+		return t.Update(instance)
+	}
+	return t.Add(instance)
+}
+
+// AddOrIgnore add the instance, or do nothing if it conflicts with an existing row
+func (t *simpleTable) AddOrIgnore(instance interface{}, opts ...orm.UpsertOption) error {
+	options := orm.UpsertOptions{}
+	for _, o := range opts {
+		o(&options)
+	}
+	keys, _, err := t.upsertColumns(options)
+	if err != nil {
+		return err
+	}
+	if clause := t.dialect.OnConflict(keys, nil); clause != "" {
+		return t.upsert(instance, keys, clause)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf(ErrConflictKeys)
+	}
+	// the database can't upsert natively, check the row exists or not
+	if err := t.Exists(instance); err == nil {
+		return nil
+	}
+	return t.Add(instance)
+}
@@ -0,0 +1,191 @@
+package tables_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/dialects"
+	"github.com/zgljl2012/go-orm/fields"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+func TestUpsert(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := table.Create(true); err != nil {
+		t.Fatal(err)
+	}
+
+	// add
+	user := User{ID: 1, Username: "username", Password: "pwd"}
+	if err := table.Upsert(&user); err != nil {
+		t.Fatal(err)
+	}
+	checkUser(t, db, table, &user)
+
+	// update
+	user.Username = "username1"
+	if err := table.Upsert(&user); err != nil {
+		t.Fatal(err)
+	}
+	checkUser(t, db, table, &user)
+
+	// keep password
+	if err := table.Upsert(&User{ID: 1, Username: "username2", Password: "new"}, orm.KeepFields("Password")); err != nil {
+		t.Fatal(err)
+	}
+	user.Username = "username2"
+	checkUser(t, db, table, &user)
+
+	// only update username
+	if err := table.Upsert(&User{ID: 1, Username: "username3", Password: "new"}, orm.UpdateFields("username")); err != nil {
+		t.Fatal(err)
+	}
+	user.Username = "username3"
+	checkUser(t, db, table, &user)
+
+	// ignore
+	if err := table.AddOrIgnore(&User{ID: 1, Username: "ignored"}); err != nil {
+		t.Fatal(err)
+	}
+	checkUser(t, db, table, &user)
+	if err := table.AddOrIgnore(&User{ID: 2, Username: "username"}); err != nil {
+		t.Fatal(err)
+	}
+	if cnt, err := table.Filter().Count(); err != nil || cnt != 2 {
+		t.Errorf("expect 2, but got %v, err: %v", cnt, err)
+	}
+
+	// unknown field
+	if err := table.Upsert(&user, orm.OnConflict("unknown")); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+}
+
+// Tag is a test table with auto increment id and unique name
+type Tag struct {
+	ID    int    `name:"id" primaryKey:"true" autoIncrement:"true"`
+	Name  string `name:"name" length:"20" unique:"true"`
+	Count int    `name:"count"`
+}
+
+func TestUpsertGeneratedID(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &Tag{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Create(false); err != nil {
+		t.Fatal(err)
+	}
+
+	// the ids of inserted and updated rows are written back
+	first := Tag{Name: "go", Count: 1}
+	if err := table.Upsert(&first, orm.OnConflict("Name")); err != nil {
+		t.Fatal(err)
+	}
+	second := Tag{Name: "sql"}
+	if err := table.AddOrIgnore(&second); err != nil {
+		t.Fatal(err)
+	}
+	updated := Tag{Name: "go", Count: 2}
+	if err := table.Upsert(&updated, orm.OnConflict("Name")); err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != 1 || second.ID != 2 || updated.ID != 1 {
+		t.Errorf("ids are wrong: %d, %d, %d", first.ID, second.ID, updated.ID)
+	}
+
+	// the id of ignored row is left zero
+	ignored := Tag{Name: "sql"}
+	if err := table.AddOrIgnore(&ignored, orm.OnConflict("Name")); err != nil {
+		t.Fatal(err)
+	}
+	if ignored.ID != 0 {
+		t.Errorf("id of ignored row should be 0, but got %d", ignored.ID)
+	}
+
+	// the id is returned by the statement on Postgres
+	postgres, err := tables.NewStructTagsTable(createRecordingDatabase(), &Tag{}, tables.WithDialect(dialects.NewPostgres()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.returns([]driver.Value{int64(7)})
+	tag := Tag{Name: "go"}
+	if err := postgres.Upsert(&tag, orm.OnConflict("Name")); err != nil {
+		t.Fatal(err)
+	}
+	expect := `INSERT INTO "Tag" ("name","count") VALUES ($1,$2) ON CONFLICT ("name") DO UPDATE SET "count"=excluded."count" RETURNING "id"`
+	if sql, _ := rec.last(); sql != expect {
+		t.Errorf("expect %v\nbut got %v", expect, sql)
+	}
+	if tag.ID != 7 {
+		t.Errorf("id should be 7, but got %d", tag.ID)
+	}
+}
+
+func TestUpsertSQL(t *testing.T) {
+	cases := []struct {
+		dialect orm.Dialect
+		action  func(table orm.Table) error
+		expect  string
+	}{
+		{
+			dialects.NewPostgres(),
+			func(table orm.Table) error {
+				return table.Upsert(&User{}, orm.OnConflict("username"), orm.UpdateFields("password", "age"), orm.KeepFields("age"))
+			},
+			`INSERT INTO "User" ("id","username","password","active","age","created_at","count") VALUES ($1,$2,$3,$4,$5,$6,$7) ` +
+				`ON CONFLICT ("username") DO UPDATE SET "password"=excluded."password"`,
+		},
+		{
+			dialects.NewSQLite(),
+			func(table orm.Table) error { return table.AddOrIgnore(&User{}) },
+			`INSERT INTO "User" ("id","username","password","active","age","created_at","count") VALUES (?,?,?,?,?,?,?) ` +
+				`ON CONFLICT ("id") DO NOTHING`,
+		},
+		{
+			dialects.NewMySQL(),
+			func(table orm.Table) error { return table.AddOrIgnore(&User{}) },
+			"INSERT INTO `User` (`id`,`username`,`password`,`active`,`age`,`created_at`,`count`) VALUES (?,?,?,?,?,?,?) " +
+				"ON DUPLICATE KEY UPDATE `id`=`id`",
+		},
+	}
+	for _, c := range cases {
+		db := createRecordingDatabase()
+		table, err := tables.NewStructTagsTable(db, &User{}, tables.WithDialect(c.dialect))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.action(table); err != nil {
+			t.Errorf("%v: %v", c.dialect.Name(), err)
+			continue
+		}
+		if sql, _ := rec.last(); sql != c.expect {
+			t.Errorf("%v:\nexpect %v\nbut got %v", c.dialect.Name(), c.expect, sql)
+		}
+	}
+
+	// MySQL can't ignore conflicts without keys
+	table, err := tables.NewTable(createRecordingDatabase(), &fieldsTable{fields: []orm.Field{
+		fields.NewCharField("Value", fields.WithUnique(true)),
+	}}, tables.WithDialect(dialects.NewMySQL()), tables.WithName("Token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.AddOrIgnore(&Token{Value: "token"}); err == nil {
+		t.Error("should got an error, but is normal")
+	}
+	if sql, _ := rec.last(); sql != "" {
+		t.Errorf("nothing should be executed, but got %v", sql)
+	}
+}
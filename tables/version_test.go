@@ -0,0 +1,136 @@
+package tables_test
+
+import (
+	"testing"
+
+	"github.com/zgljl2012/go-orm"
+	"github.com/zgljl2012/go-orm/fields"
+	"github.com/zgljl2012/go-orm/tables"
+)
+
+// Stock is a test table with version
+type Stock struct {
+	ID       int    `name:"id" primaryKey:"true"`
+	Name     string `name:"name" length:"20"`
+	Quantity int    `name:"quantity"`
+	Version  int    `name:"version" version:"true"`
+}
+
+func TestVersion(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewStructTagsTable(db, &Stock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Add(&Stock{ID: 1, Name: "apple", Quantity: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := Stock{}, Stock{}
+	if err := table.Filter(orm.WithParameter("ID", 1)).Get(&first); err != nil {
+		t.Fatal(err)
+	}
+	second = first
+
+	first.Quantity = 9
+	if err := table.Update(&first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Version != 1 {
+		t.Errorf("version should be 1, but got %d", first.Version)
+	}
+
+	second.Quantity = 8
+	if err := table.Update(&second); err != orm.ErrStaleObject {
+		t.Errorf("expect ErrStaleObject, but got %v", err)
+	}
+	if second.Version != 0 {
+		t.Errorf("version of stale object should not be changed: %d", second.Version)
+	}
+
+	// retry after reading again
+	if err := table.Filter(orm.WithParameter("ID", 1)).Get(&second); err != nil {
+		t.Fatal(err)
+	}
+	second.Quantity--
+	if err := table.Update(&second); err != nil {
+		t.Fatal(err)
+	}
+	got := Stock{}
+	if err := table.Filter(orm.WithParameter("ID", 1)).Get(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Quantity != 8 || got.Version != 2 {
+		t.Errorf("row is wrong: %+v", got)
+	}
+
+	// bulk update increases the version, so the objects read before are stale
+	if n, err := table.Filter(orm.WithParameter("ID", 1)).Update(map[string]interface{}{"Quantity": 7}); err != nil || n != 1 {
+		t.Fatalf("expect 1 row updated, but got %d: %v", n, err)
+	}
+	got.Quantity = 6
+	if err := table.Update(&got); err != orm.ErrStaleObject {
+		t.Errorf("expect ErrStaleObject after bulk update, but got %v", err)
+	}
+	if err := table.Filter(orm.WithParameter("ID", 1)).Get(&got); err != nil || got.Quantity != 7 || got.Version != 3 {
+		t.Errorf("row is wrong after bulk update: %+v, %v", got, err)
+	}
+
+	// upsert can't check the version
+	if err := table.Upsert(&Stock{ID: 1, Name: "apple", Quantity: 100}); err == nil {
+		t.Error("upsert should be rejected")
+	}
+	if err := table.Filter(orm.WithParameter("ID", 1)).Get(&got); err != nil || got.Quantity != 7 || got.Version != 3 {
+		t.Errorf("row should not be changed by upsert: %+v, %v", got, err)
+	}
+}
+
+// Ticket is a test table with unsigned version
+type Ticket struct {
+	ID      int
+	Version uint32
+}
+
+func TestVersionUnsigned(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	table, err := tables.NewTable(db, &fieldsTable{fields: []orm.Field{
+		fields.NewIntField("ID", fields.WithPrimaryKey(true)),
+		fields.NewIntField("Version", fields.WithVersion(true)),
+	}}, tables.WithName("Ticket"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Create(false); err != nil {
+		t.Fatal(err)
+	}
+	ticket := Ticket{ID: 1}
+	if err := table.Add(&ticket); err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Update(&ticket); err != nil {
+		t.Fatal(err)
+	}
+	if ticket.Version != 1 {
+		t.Errorf("version should be 1, but got %d", ticket.Version)
+	}
+}
+
+func TestVersionTagError(t *testing.T) {
+	db := createTestDatabase()
+	defer deleteTestDatabase()
+
+	_, err := tables.NewStructTagsTable(db, &struct {
+		ID      int    `name:"id" primaryKey:"true"`
+		Version string `name:"version" length:"10" version:"true"`
+	}{})
+	if err == nil {
+		t.Error("version of string field should be rejected")
+	}
+}
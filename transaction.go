@@ -0,0 +1,61 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Session is a unit of work bound to one database transaction
+type Session interface {
+	// Tx the underlying transaction
+	Tx() *sql.Tx
+	// Context the context which the transaction began with, statements run by Tx should use it
+	Context() context.Context
+	// Table return a copy of table whose operations run inside this session with its context
+	Table(table Table) Table
+}
+
+type session struct {
+	ctx context.Context
+	tx  *sql.Tx
+}
+
+func (s *session) Tx() *sql.Tx {
+	return s.tx
+}
+
+func (s *session) Context() context.Context {
+	return s.ctx
+}
+
+func (s *session) Table(table Table) Table {
+	return table.WithContext(s.ctx).WithTx(s.tx)
+}
+
+// Transaction run fn in a transaction, the transaction will be committed if fn returns nil,
+// or rolled back if fn returns an error or panics.
+func Transaction(db *sql.DB, fn func(tx Session) error) error {
+	return TransactionContext(context.Background(), db, fn)
+}
+
+// TransactionContext is the same as Transaction, but begins the transaction with ctx
+func TransactionContext(ctx context.Context, db *sql.DB, fn func(tx Session) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+	if err := fn(&session{ctx: ctx, tx: tx}); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%v, and rollback failed: %v", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,35 @@
+package orm
+
+// UpsertOptions options of upsert
+type UpsertOptions struct {
+	// Conflict the fields of a unique constraint, default is the primary keys
+	Conflict []string
+	// Update the fields to overwrite when conflict, default is all fields except conflict ones
+	Update []string
+	// Keep the fields to keep when conflict, they are excluded from Update
+	Keep []string
+}
+
+// UpsertOption option setter
+type UpsertOption func(options *UpsertOptions)
+
+// OnConflict specify the conflict target, which should be a unique constraint
+func OnConflict(fields ...string) UpsertOption {
+	return func(options *UpsertOptions) {
+		options.Conflict = fields
+	}
+}
+
+// UpdateFields specify the fields to overwrite when conflict
+func UpdateFields(fields ...string) UpsertOption {
+	return func(options *UpsertOptions) {
+		options.Update = fields
+	}
+}
+
+// KeepFields specify the fields to keep when conflict
+func KeepFields(fields ...string) UpsertOption {
+	return func(options *UpsertOptions) {
+		options.Keep = fields
+	}
+}